@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// getAdminUser authenticates the request and checks that the caller is an
+// admin. It writes the error response itself and reports whether the
+// handler may continue.
+func (cfg *apiConfig) getAdminUser(w http.ResponseWriter, r *http.Request) (database.User, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return database.User{}, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return database.User{}, false
+	}
+
+	user, err := cfg.db.GetUserById(r.Context(), userId)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized user", err)
+		return database.User{}, false
+	}
+
+	if !user.IsAdmin {
+		respondWithError(w, http.StatusForbidden, "Action is not permitted", nil)
+		return database.User{}, false
+	}
+
+	return user, true
+}
+
+func (cfg *apiConfig) handlerRestoreChirp(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	chirp, err := qtx.RestoreChirp(r.Context(), chirpUUID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "No deleted chirp with that id", err)
+		return
+	}
+
+	err = logModerationAction(r.Context(), qtx, database.LogModerationActionParams{
+		ModeratorID:  admin.ID,
+		Action:       "restore_chirp",
+		ChirpID:      nullUUID(chirp.ID),
+		TargetUserID: nullUUID(chirp.UserID),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to restore chirp", err)
+		return
+	}
+
+	resp, err := cfg.singleChirpResponse(r.Context(), chirp, uuid.Nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+const maxChirpImages = 4
+
+type attachmentVals struct {
+	ID           string `json:"id"`
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	ContentType  string `json:"content_type"`
+	Width        int32  `json:"width"`
+	Height       int32  `json:"height"`
+}
+
+// upload is a processed image waiting to be attached to a new chirp.
+type upload struct {
+	image        *media.Image
+	key          string
+	thumbnailKey string
+}
+
+func mediaURL(key string) string {
+	return "/media/" + key
+}
+
+func isMultipart(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "multipart/form-data"
+}
+
+// decodeChirpForm reads a multipart POST /api/chirps request. The "chirp"
+// field holds the same JSON as a plain request and each "images" file is
+// one attachment. On failure it writes the error response and returns false.
+func decodeChirpForm(w http.ResponseWriter, r *http.Request) (chirpParams, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxChirpImages*media.MaxImageBytes+1<<20)
+	if err := r.ParseMultipartForm(8 << 20); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse multipart form", err)
+		return chirpParams{}, false
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	params := chirpParams{}
+	if raw := r.FormValue("chirp"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &params); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Couldn't decode chirp field", err)
+			return chirpParams{}, false
+		}
+	}
+
+	files := r.MultipartForm.File["images"]
+	if len(files) > maxChirpImages {
+		respondWithError(w, http.StatusBadRequest, "A chirp can have at most 4 images", nil)
+		return chirpParams{}, false
+	}
+
+	for _, header := range files {
+		f, err := header.Open()
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Couldn't read image", err)
+			return chirpParams{}, false
+		}
+		img, err := media.Process(f)
+		f.Close()
+		if errors.Is(err, media.ErrUnsupportedType) {
+			respondWithError(w, http.StatusBadRequest, "Images must be JPEG, PNG or GIF", err)
+			return chirpParams{}, false
+		}
+		if errors.Is(err, media.ErrTooLarge) {
+			respondWithError(w, http.StatusRequestEntityTooLarge, "Image is too large", err)
+			return chirpParams{}, false
+		}
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't process image", err)
+			return chirpParams{}, false
+		}
+
+		id := uuid.New().String()
+		params.uploads = append(params.uploads, upload{
+			image:        img,
+			key:          id + "." + img.Ext,
+			thumbnailKey: id + "_thumb." + img.ThumbnailExt,
+		})
+	}
+
+	return params, true
+}
+
+// storeUploads writes the images and thumbnails to the blob store. Blobs
+// written before a failure are removed again.
+func (cfg *apiConfig) storeUploads(ctx context.Context, uploads []upload) error {
+	for i, u := range uploads {
+		err := cfg.media.Put(ctx, u.key, bytes.NewReader(u.image.Data))
+		if err == nil {
+			err = cfg.media.Put(ctx, u.thumbnailKey, bytes.NewReader(u.image.Thumbnail))
+		}
+		if err != nil {
+			cfg.deleteUploads(ctx, uploads[:i+1])
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUploads removes blobs of a chirp that was never saved.
+func (cfg *apiConfig) deleteUploads(ctx context.Context, uploads []upload) {
+	for _, u := range uploads {
+		cfg.deleteBlobs(ctx, u.key, u.thumbnailKey)
+	}
+}
+
+// deleteBlobs removes blobs that no row references any more. Failures only
+// leave unreferenced files behind, so they are logged.
+func (cfg *apiConfig) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := cfg.media.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete blob %s: %s", key, err)
+		}
+	}
+}
+
+func saveAttachments(ctx context.Context, qtx *database.Queries, chirp database.Chirp, uploads []upload) error {
+	for i, u := range uploads {
+		err := qtx.CreateChirpAttachment(ctx, database.CreateChirpAttachmentParams{
+			ID:           uuid.New(),
+			ChirpID:      chirp.ID,
+			Position:     int32(i),
+			CreatedAt:    time.Now().UTC(),
+			ContentType:  u.image.ContentType,
+			Width:        int32(u.image.Width),
+			Height:       int32(u.image.Height),
+			BlobKey:      u.key,
+			ThumbnailKey: u.thumbnailKey,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attachmentResponses loads the attachments of the given chirps in one
+// query, in the order they were uploaded.
+func (cfg *apiConfig) attachmentResponses(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID][]attachmentVals, error) {
+	rows, err := cfg.db.GetChirpAttachments(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := map[uuid.UUID][]attachmentVals{}
+	for _, row := range rows {
+		attachments[row.ChirpID] = append(attachments[row.ChirpID], attachmentVals{
+			ID:           row.ID.String(),
+			URL:          mediaURL(row.BlobKey),
+			ThumbnailURL: mediaURL(row.ThumbnailKey),
+			ContentType:  row.ContentType,
+			Width:        row.Width,
+			Height:       row.Height,
+		})
+	}
+	return attachments, nil
+}
+
+// handlerGetMedia serves a blob by key to anyone who may see the chirp it
+// is attached to. Only blobs of public chirps may be kept by shared caches,
+// and only for a while, as the chirp can still be deleted or hidden.
+func (cfg *apiConfig) handlerGetMedia(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	access, err := cfg.db.GetMediaAccess(r.Context(), database.GetMediaAccessParams{
+		ViewerID: cfg.viewerID(r),
+		Key:      key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Media not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get media", err)
+		return
+	}
+	if !access.Visible {
+		respondWithError(w, http.StatusNotFound, "Media not found", nil)
+		return
+	}
+
+	f, err := cfg.media.Open(r.Context(), key)
+	if errors.Is(err, media.ErrNotFound) || errors.Is(err, media.ErrInvalidKey) {
+		respondWithError(w, http.StatusNotFound, "Media not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to open media", err)
+		return
+	}
+	defer f.Close()
+
+	if access.Visibility == database.ChirpVisibilityPublic {
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+	} else {
+		w.Header().Set("Cache-Control", "private, max-age=3600")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, key, time.Time{}, f)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func multipartChirp(t *testing.T, chirp string, images ...[]byte) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("chirp", chirp); err != nil {
+		t.Fatalf("WriteField() error = %v", err)
+	}
+	for _, data := range images {
+		part, err := form.CreateFormFile("images", "upload.png")
+		if err != nil {
+			t.Fatalf("CreateFormFile() error = %v", err)
+		}
+		part.Write(data)
+	}
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req
+}
+
+func TestDecodeChirpForm(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	pngData := buf.Bytes()
+
+	tests := []struct {
+		name        string
+		chirp       string
+		images      [][]byte
+		wantStatus  int
+		wantUploads int
+	}{
+		{name: "no images", chirp: `{"body": "hello"}`, wantUploads: 0},
+		{name: "two images", chirp: `{"body": "hello"}`, images: [][]byte{pngData, pngData}, wantUploads: 2},
+		{name: "too many images", chirp: `{"body": "hello"}`, images: [][]byte{pngData, pngData, pngData, pngData, pngData}, wantStatus: http.StatusBadRequest},
+		{name: "not an image", chirp: `{"body": "hello"}`, images: [][]byte{[]byte("<html>hi</html>")}, wantStatus: http.StatusBadRequest},
+		{name: "bad chirp json", chirp: `{"body": `, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := multipartChirp(t, tt.chirp, tt.images...)
+			if !isMultipart(req) {
+				t.Fatalf("isMultipart() = false for %q", req.Header.Get("Content-Type"))
+			}
+
+			rec := httptest.NewRecorder()
+			params, ok := decodeChirpForm(rec, req)
+			if tt.wantStatus != 0 {
+				if ok || rec.Code != tt.wantStatus {
+					t.Errorf("decodeChirpForm() = %v, status %d, want status %d", ok, rec.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("decodeChirpForm() failed: %d %s", rec.Code, rec.Body)
+			}
+			if params.Body != "hello" || len(params.uploads) != tt.wantUploads {
+				t.Errorf("decodeChirpForm() = body %q, %d uploads, want %q, %d", params.Body, len(params.uploads), "hello", tt.wantUploads)
+			}
+			for _, u := range params.uploads {
+				if u.key == u.thumbnailKey || u.image.Width != 8 {
+					t.Errorf("upload = %+v, want distinct keys and an 8px image", u)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareHidePath(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := http.StripPrefix("/app", middlewareHidePath("/media", next))
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{target: "/app/index.html", want: http.StatusOK},
+		{target: "/app/mediakit.html", want: http.StatusOK},
+		{target: "/app/media", want: http.StatusNotFound},
+		{target: "/app/media/", want: http.StatusNotFound},
+		{target: "/app/media/abc.png", want: http.StatusNotFound},
+		{target: "/app/Media/abc.png", want: http.StatusNotFound},
+		{target: "/app/assets/../media/abc.png", want: http.StatusNotFound},
+		{target: "/app/%6dedia/", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.target, rec.Code, tt.want)
+		}
+	}
+}
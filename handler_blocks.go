@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+type relationVals struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type relationsPage struct {
+	Users      []relationVals `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// relationTarget authenticates the caller and resolves the {userID} path
+// value to an existing user other than the caller.
+func (cfg *apiConfig) relationTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, database.User, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return uuid.Nil, database.User{}, false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return uuid.Nil, database.User{}, false
+	}
+
+	targetUUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse user id", err)
+		return uuid.Nil, database.User{}, false
+	}
+
+	if targetUUID == userId {
+		respondWithError(w, http.StatusBadRequest, "You can't do that to yourself", nil)
+		return uuid.Nil, database.User{}, false
+	}
+
+	target, err := cfg.db.GetUserById(r.Context(), targetUUID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return uuid.Nil, database.User{}, false
+	}
+
+	return userId, target, true
+}
+
+func (cfg *apiConfig) handlerBlockUser(w http.ResponseWriter, r *http.Request) {
+	userId, target, ok := cfg.relationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to block user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	if err := lockUsers(r.Context(), qtx, userId, target.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to block user", err)
+		return
+	}
+
+	err = qtx.BlockUser(r.Context(), database.BlockUserParams{
+		BlockerID: userId,
+		BlockedID: target.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to block user", err)
+		return
+	}
+
+	// A block severs the follow graph in both directions.
+	err = qtx.DeleteFollowsBetween(r.Context(), database.DeleteFollowsBetweenParams{
+		UserA: userId,
+		UserB: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to block user", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to block user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// lockUsers locks both users' rows, in a fixed order so that two
+// transactions locking the same pair can't deadlock. Blocking and following
+// take these locks, so a follow can't be saved after a concurrent block
+// between the same users has already removed their follows.
+func lockUsers(ctx context.Context, qtx *database.Queries, a, b uuid.UUID) error {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	if err := qtx.LockUser(ctx, a); err != nil {
+		return err
+	}
+	return qtx.LockUser(ctx, b)
+}
+
+func (cfg *apiConfig) handlerUnblockUser(w http.ResponseWriter, r *http.Request) {
+	userId, target, ok := cfg.relationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.UnblockUser(r.Context(), database.UnblockUserParams{
+		BlockerID: userId,
+		BlockedID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unblock user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerMuteUser(w http.ResponseWriter, r *http.Request) {
+	userId, target, ok := cfg.relationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.MuteUser(r.Context(), database.MuteUserParams{
+		MuterID:   userId,
+		MutedID:   target.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to mute user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnmuteUser(w http.ResponseWriter, r *http.Request) {
+	userId, target, ok := cfg.relationTarget(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.UnmuteUser(r.Context(), database.UnmuteUserParams{
+		MuterID: userId,
+		MutedID: target.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unmute user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetBlockedUsers(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetBlockedUsers(r.Context(), database.GetBlockedUsersParams{
+		UserID:          userId,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get blocked users", err)
+		return
+	}
+
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.GetBlockedUsersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+	})
+	users := []relationVals{}
+	for _, row := range rows {
+		users = append(users, relationVals{
+			ID:        row.ID.String(),
+			Username:  row.Username,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, relationsPage{Users: users, NextCursor: nextCursor})
+}
+
+func (cfg *apiConfig) handlerGetMutedUsers(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetMutedUsers(r.Context(), database.GetMutedUsersParams{
+		UserID:          userId,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get muted users", err)
+		return
+	}
+
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.GetMutedUsersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+	})
+	users := []relationVals{}
+	for _, row := range rows {
+		users = append(users, relationVals{
+			ID:        row.ID.String(),
+			Username:  row.Username,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, relationsPage{Users: users, NextCursor: nextCursor})
+}
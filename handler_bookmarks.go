@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerBookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+
+	err = cfg.db.BookmarkChirp(r.Context(), database.BookmarkChirpParams{
+		UserID:    userId,
+		ChirpID:   chirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to bookmark chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnbookmarkChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	err = cfg.db.UnbookmarkChirp(r.Context(), database.UnbookmarkChirpParams{
+		UserID:  userId,
+		ChirpID: chirpUUID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to remove bookmark", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetMyBookmarks(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetBookmarkedChirps(r.Context(), database.GetBookmarkedChirpsParams{
+		UserID:          userId,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get bookmarks", err)
+		return
+	}
+
+	// Bookmarks are paged in the order they were saved, not by chirp age.
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.GetBookmarkedChirpsRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.BookmarkedAt, ID: row.Chirp.ID}
+	})
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+	resp := chirpsPage{Chirps: chirpResps, NextCursor: nextCursor}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerGetChirpRevisions(w http.ResponseWriter, r *http.Request) {
+	type revision struct {
+		ID        string    `json:"id"`
+		ChirpID   string    `json:"chirp_id"`
+		CreatedAt time.Time `json:"created_at"`
+		Body      string    `json:"body"`
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: cfg.viewerID(r),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+
+	revisions, err := cfg.db.GetChirpRevisions(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp revisions", err)
+		return
+	}
+
+	allRevisions := []revision{}
+	for _, rev := range revisions {
+		allRevisions = append(allRevisions, revision{
+			ID:        rev.ID.String(),
+			ChirpID:   rev.ChirpID.String(),
+			CreatedAt: rev.CreatedAt,
+			Body:      rev.Body,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, allRevisions)
+}
@@ -1,25 +1,514 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/chirptext"
 	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/moderation"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/geolunalg/gochirpy/internal/timeline"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type returnVals struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	Body      string    `json:"body"`
-	UserID    string    `json:"user_id"`
+	ID         string     `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	Body       string     `json:"body"`
+	UserID     string     `json:"user_id"`
+	InReplyTo  string     `json:"in_reply_to,omitempty"`
+	Visibility string     `json:"visibility"`
+	PublishAt  *time.Time `json:"publish_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LikeCount  int64      `json:"like_count"`
+	LikedByMe  bool       `json:"liked_by_me"`
+	Poll       *pollVals  `json:"poll,omitempty"`
+
+	Attachments []attachmentVals `json:"attachments,omitempty"`
+
+	Reactions map[string]int64 `json:"reactions"`
+
+	RepostedChirp *embeddedChirp `json:"reposted_chirp,omitempty"`
+}
+
+// embeddedChirp is the original of a rechirp or quote-chirp. If the
+// original is gone only its id is kept and Unavailable is set.
+type embeddedChirp struct {
+	*returnVals
+	ID          string `json:"id"`
+	Unavailable bool   `json:"unavailable,omitempty"`
+}
+
+var (
+	errChirpTooLong  = errors.New("chirp is too long")
+	errChirpTooLarge = errors.New("chirp is too large")
+)
+
+// parseVisibility maps the optional visibility field of a new chirp to its
+// database value. Chirps are public unless asked otherwise.
+func parseVisibility(raw string) (database.ChirpVisibility, error) {
+	switch visibility := database.ChirpVisibility(raw); visibility {
+	case "":
+		return database.ChirpVisibilityPublic, nil
+	case database.ChirpVisibilityPublic, database.ChirpVisibilityFollowers, database.ChirpVisibilityMentioned:
+		return visibility, nil
+	default:
+		return "", fmt.Errorf("unknown visibility %q", raw)
+	}
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// violatedConstraint names the constraint a database error is about, or
+// returns "" for other errors.
+func violatedConstraint(err error) string {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint
+	}
+	return ""
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23503"
+}
+
+func chirpResponse(chirp database.Chirp) returnVals {
+	resp := returnVals{
+		ID:         chirp.ID.String(),
+		CreatedAt:  chirp.CreatedAt,
+		UpdatedAt:  chirp.UpdatedAt,
+		Body:       chirp.Body,
+		UserID:     chirp.UserID.String(),
+		Visibility: string(chirp.Visibility),
+	}
+	if chirp.InReplyTo.Valid {
+		resp.InReplyTo = chirp.InReplyTo.UUID.String()
+	}
+	if chirp.PublishAt.Valid {
+		resp.PublishAt = &chirp.PublishAt.Time
+	}
+	if chirp.ExpiresAt.Valid {
+		resp.ExpiresAt = &chirp.ExpiresAt.Time
+	}
+	return resp
+}
+
+// chirpResponses converts chirps to responses for viewerID, who may be
+// uuid.Nil for anonymous callers. Reposted originals and counters are
+// loaded for the whole slice at once rather than with queries per chirp.
+func (cfg *apiConfig) chirpResponses(ctx context.Context, chirps []database.Chirp, viewerID uuid.UUID) ([]returnVals, error) {
+	resps := make([]returnVals, 0, len(chirps))
+	if len(chirps) == 0 {
+		return resps, nil
+	}
+
+	originalIDs := []uuid.UUID{}
+	for _, chirp := range chirps {
+		if chirp.RepostedChirpID.Valid {
+			originalIDs = append(originalIDs, chirp.RepostedChirpID.UUID)
+		}
+	}
+
+	originals := []database.Chirp{}
+	if len(originalIDs) > 0 {
+		var err error
+		originals, err = cfg.db.GetChirpsByIds(ctx, database.GetChirpsByIdsParams{
+			Ids:      originalIDs,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]uuid.UUID, 0, len(chirps)+len(originals))
+	for _, chirp := range chirps {
+		ids = append(ids, chirp.ID)
+	}
+	for _, original := range originals {
+		ids = append(ids, original.ID)
+	}
+
+	likes, err := cfg.db.GetChirpLikeStats(ctx, database.GetChirpLikeStatsParams{
+		ViewerID: viewerID,
+		ChirpIds: ids,
+	})
+	if err != nil {
+		return nil, err
+	}
+	likesByChirp := make(map[uuid.UUID]database.GetChirpLikeStatsRow, len(likes))
+	for _, like := range likes {
+		likesByChirp[like.ChirpID] = like
+	}
+
+	polls, err := cfg.pollResponses(ctx, ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := cfg.reactionCounts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := cfg.attachmentResponses(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	withCounters := func(chirp database.Chirp) returnVals {
+		resp := chirpResponse(chirp)
+		resp.LikeCount = likesByChirp[chirp.ID].LikeCount
+		resp.LikedByMe = likesByChirp[chirp.ID].LikedByMe
+		resp.Poll = polls[chirp.ID]
+		resp.Reactions = reactions[chirp.ID]
+		resp.Attachments = attachments[chirp.ID]
+		return resp
+	}
+
+	originalsByID := make(map[uuid.UUID]returnVals, len(originals))
+	for _, original := range originals {
+		originalsByID[original.ID] = withCounters(original)
+	}
+
+	for _, chirp := range chirps {
+		resp := withCounters(chirp)
+		if chirp.RepostedChirpID.Valid {
+			embedded := &embeddedChirp{ID: chirp.RepostedChirpID.UUID.String()}
+			if original, ok := originalsByID[chirp.RepostedChirpID.UUID]; ok {
+				embedded.returnVals = &original
+			} else {
+				embedded.Unavailable = true
+			}
+			resp.RepostedChirp = embedded
+		}
+		resps = append(resps, resp)
+	}
+
+	return resps, nil
+}
+
+func (cfg *apiConfig) singleChirpResponse(ctx context.Context, chirp database.Chirp, viewerID uuid.UUID) (returnVals, error) {
+	resps, err := cfg.chirpResponses(ctx, []database.Chirp{chirp}, viewerID)
+	if err != nil {
+		return returnVals{}, err
+	}
+	return resps[0], nil
+}
+
+// publishToTimelines fans a newly published chirp out to home timelines.
+// Failures are only logged since the chirp itself is already saved.
+func (cfg *apiConfig) publishToTimelines(ctx context.Context, chirp database.Chirp) {
+	err := cfg.timelines.Publish(ctx, chirp.UserID, timeline.Entry{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+	})
+	if err != nil {
+		log.Printf("Failed to publish chirp %s to timelines: %s", chirp.ID, err)
+	}
+}
+
+// viewerID identifies the caller of a public endpoint. Requests without a
+// valid bearer token are treated as anonymous and get uuid.Nil.
+func (cfg *apiConfig) viewerID(r *http.Request) uuid.UUID {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	return userId
+}
+
+// chirpParams describes a new chirp, as posted to POST /api/chirps or
+// taken from a draft being published. Images only arrive in multipart
+// requests, so uploads is never set from JSON.
+type chirpParams struct {
+	Body            string      `json:"body"`
+	InReplyTo       string      `json:"in_reply_to"`
+	RepostedChirpID string      `json:"reposted_chirp_id"`
+	Visibility      string      `json:"visibility"`
+	PublishAt       *time.Time  `json:"publish_at"`
+	ExpiresIn       string      `json:"expires_in"`
+	Poll            *pollParams `json:"poll"`
+
+	uploads []upload
 }
 
 func (cfg *apiConfig) handlerAddChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	params := chirpParams{}
+	if isMultipart(r) {
+		var ok bool
+		params, ok = decodeChirpForm(w, r)
+		if !ok {
+			return
+		}
+	} else {
+		decoder := json.NewDecoder(r.Body)
+		err = decoder.Decode(&params)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+			return
+		}
+	}
+
+	chirp, ok := cfg.createChirp(w, r, userId, params, uuid.Nil)
+	if !ok {
+		return
+	}
+
+	resp, err := cfg.singleChirpResponse(r.Context(), chirp, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get reposted chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp)
+}
+
+// createChirp validates params and saves them as a new chirp by userId. On
+// failure it writes the error response and returns false. A non-nil draftID
+// is deleted in the same transaction, so a draft is published only once.
+func (cfg *apiConfig) createChirp(w http.ResponseWriter, r *http.Request, userId uuid.UUID, params chirpParams, draftID uuid.UUID) (database.Chirp, bool) {
+	cleaned, err := cfg.validateChirpBody(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, chirpBodyError(err), err)
+		return database.Chirp{}, false
+	}
+
+	visibility, err := parseVisibility(params.Visibility)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "visibility must be public, followers or mentioned", err)
+		return database.Chirp{}, false
+	}
+
+	// Chirps with a publish_at stay hidden until the scheduler publishes them.
+	publishAt := sql.NullTime{}
+	if params.PublishAt != nil {
+		if !params.PublishAt.After(time.Now()) {
+			respondWithError(w, http.StatusBadRequest, "publish_at must be in the future", nil)
+			return database.Chirp{}, false
+		}
+		publishAt = sql.NullTime{Time: params.PublishAt.UTC(), Valid: true}
+	}
+
+	// expires_in counts from when the chirp becomes visible.
+	expiresAt := sql.NullTime{}
+	if params.ExpiresIn != "" {
+		expiresIn, err := time.ParseDuration(params.ExpiresIn)
+		if err != nil || expiresIn <= 0 {
+			respondWithError(w, http.StatusBadRequest, "expires_in must be a positive duration such as 90m or 24h", err)
+			return database.Chirp{}, false
+		}
+		visibleFrom := time.Now().UTC()
+		if publishAt.Valid {
+			visibleFrom = publishAt.Time
+		}
+		expiresAt = sql.NullTime{Time: visibleFrom.Add(expiresIn), Valid: true}
+	}
+
+	var pollOptions []string
+	if params.Poll != nil {
+		if cleaned == "" {
+			respondWithError(w, http.StatusBadRequest, "A chirp with a poll needs a body", nil)
+			return database.Chirp{}, false
+		}
+		opensAt := time.Now().UTC()
+		if publishAt.Valid {
+			opensAt = publishAt.Time
+		}
+		pollOptions, err = cfg.validatePollOptions(*params.Poll, opensAt)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error(), err)
+			return database.Chirp{}, false
+		}
+	}
+
+	if len(params.uploads) > 0 && cleaned == "" && params.RepostedChirpID != "" {
+		respondWithError(w, http.StatusBadRequest, "A rechirp can't have images", nil)
+		return database.Chirp{}, false
+	}
+
+	// Blobs are written before the transaction and removed again unless
+	// the chirp referencing them is committed.
+	committed := false
+	if len(params.uploads) > 0 {
+		if err := cfg.storeUploads(r.Context(), params.uploads); err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to store images", err)
+			return database.Chirp{}, false
+		}
+		defer func() {
+			if !committed {
+				cfg.deleteUploads(context.WithoutCancel(r.Context()), params.uploads)
+			}
+		}()
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create new chirp", err)
+		return database.Chirp{}, false
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	if draftID != uuid.Nil {
+		deleted, err := qtx.DeleteDraft(r.Context(), database.DeleteDraftParams{
+			ID:     draftID,
+			UserID: userId,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to publish draft", err)
+			return database.Chirp{}, false
+		}
+		if deleted == 0 {
+			respondWithError(w, http.StatusNotFound, "Draft not found", nil)
+			return database.Chirp{}, false
+		}
+	}
+
+	inReplyTo := uuid.NullUUID{}
+	if params.InReplyTo != "" {
+		parentUUID, err := uuid.Parse(params.InReplyTo)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Couldn't parse in_reply_to", err)
+			return database.Chirp{}, false
+		}
+
+		parent, err := qtx.GetChirpById(r.Context(), database.GetChirpByIdParams{
+			ID:       parentUUID,
+			ViewerID: userId,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Chirp being replied to does not exist", err)
+			return database.Chirp{}, false
+		}
+		inReplyTo = uuid.NullUUID{UUID: parent.ID, Valid: true}
+	}
+
+	// A chirp with a reposted_chirp_id is a quote-chirp, or a plain rechirp
+	// when it has no text of its own.
+	repostedChirpID := uuid.NullUUID{}
+	if params.RepostedChirpID != "" {
+		originalUUID, err := uuid.Parse(params.RepostedChirpID)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Couldn't parse reposted_chirp_id", err)
+			return database.Chirp{}, false
+		}
+
+		original, err := qtx.GetChirpById(r.Context(), database.GetChirpByIdParams{
+			ID:       originalUUID,
+			ViewerID: userId,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Chirp being reposted does not exist", err)
+			return database.Chirp{}, false
+		}
+
+		if cleaned == "" && inReplyTo.Valid {
+			respondWithError(w, http.StatusBadRequest, "A rechirp can't be a reply", nil)
+			return database.Chirp{}, false
+		}
+
+		// Rechirping a rechirp reposts the chirp it points at.
+		if original.Body == "" && original.RepostedChirpID.Valid {
+			repostedChirpID = original.RepostedChirpID
+		} else {
+			repostedChirpID = uuid.NullUUID{UUID: original.ID, Valid: true}
+		}
+	}
+
+	chirp, err := qtx.CreateChirp(r.Context(), database.CreateChirpParams{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now().UTC(),
+		UpdatedAt:       time.Now().UTC(),
+		Body:            cleaned,
+		UserID:          userId,
+		InReplyTo:       inReplyTo,
+		RepostedChirpID: repostedChirpID,
+		Visibility:      visibility,
+		PublishAt:       publishAt,
+		ExpiresAt:       expiresAt,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Chirp already rechirped", err)
+		return database.Chirp{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to create new chirp", err)
+		return database.Chirp{}, false
+	}
+
+	if params.Poll != nil {
+		err = savePoll(r.Context(), qtx, chirp, params.Poll.ClosesAt.UTC(), pollOptions)
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to save poll", err)
+			return database.Chirp{}, false
+		}
+	}
+
+	err = saveAttachments(r.Context(), qtx, chirp, params.uploads)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save images", err)
+		return database.Chirp{}, false
+	}
+
+	err = saveChirpHashtags(r.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save hashtags", err)
+		return database.Chirp{}, false
+	}
+
+	err = saveChirpMentions(r.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save mentions", err)
+		return database.Chirp{}, false
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create new chirp", err)
+		return database.Chirp{}, false
+	}
+	committed = true
+
+	if !chirp.PublishAt.Valid {
+		cfg.publishToTimelines(r.Context(), chirp)
+	}
+
+	return chirp, true
+}
+
+func (cfg *apiConfig) handlerUpdateChirp(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
 		Body string `json:"body"`
 	}
@@ -36,6 +525,12 @@ func (cfg *apiConfig) handlerAddChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
 	decoder := json.NewDecoder(r.Body)
 	params := parameters{}
 	err = decoder.Decode(&params)
@@ -44,74 +539,331 @@ func (cfg *apiConfig) handlerAddChirp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	const maxChirpLength = 140
-	if len(params.Body) > maxChirpLength {
-		respondWithError(w, http.StatusBadRequest, "Chirp is too long", nil)
+	// An empty body would turn a quote chirp into a plain rechirp.
+	if params.Body == "" {
+		respondWithError(w, http.StatusBadRequest, "Chirp body is required", nil)
+		return
+	}
+
+	cleaned, err := cfg.validateChirpBody(params.Body)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, chirpBodyError(err), err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	current, err := qtx.GetChirpByIdForUpdate(r.Context(), chirpUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp", err)
+		return
+	}
+
+	if current.UserID != userId {
+		// Chirps the caller can't see don't exist as far as they know.
+		visible, err := qtx.ChirpVisibleTo(r.Context(), database.ChirpVisibleToParams{
+			ChirpID:  current.ID,
+			ViewerID: userId,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to update chirp", err)
+			return
+		}
+		if !visible {
+			respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", nil)
+			return
+		}
+		respondWithError(w, http.StatusForbidden, "Chirp belongs to another user", nil)
+		return
+	}
+
+	if current.Body == "" && current.RepostedChirpID.Valid {
+		respondWithError(w, http.StatusBadRequest, "Rechirps can't be edited", nil)
 		return
 	}
 
-	badWords := map[string]struct{}{
-		"kerfuffle": {},
-		"sharbert":  {},
-		"fornax":    {},
+	// Hidden and expired chirps are frozen, and scheduled ones are replaced
+	// by cancelling them rather than edited before they go out.
+	switch {
+	case current.HiddenAt.Valid:
+		respondWithError(w, http.StatusConflict, "Hidden chirps can't be edited", nil)
+		return
+	case current.ExpiresAt.Valid && !current.ExpiresAt.Time.After(time.Now().UTC()):
+		respondWithError(w, http.StatusConflict, "Expired chirps can't be edited", nil)
+		return
+	case current.PublishAt.Valid:
+		respondWithError(w, http.StatusConflict, "Scheduled chirps can't be edited", nil)
+		return
 	}
-	cleaned := getCleanedBody(params.Body, badWords)
 
-	chirp, err := cfg.db.CreateChirp(r.Context(), database.CreateChirpParams{
+	_, err = qtx.CreateChirpRevision(r.Context(), database.CreateChirpRevisionParams{
 		ID:        uuid.New(),
-		CreatedAt: time.Now().UTC(),
-		UpdatedAt: time.Now().UTC(),
+		ChirpID:   current.ID,
+		CreatedAt: current.UpdatedAt,
+		Body:      current.Body,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save chirp revision", err)
+		return
+	}
+
+	chirp, err := qtx.UpdateChirp(r.Context(), database.UpdateChirpParams{
+		ID:        current.ID,
 		Body:      cleaned,
-		UserID:    userId,
+		UpdatedAt: time.Now().UTC(),
 	})
 	if err != nil {
-		respondWithError(w, http.StatusBadRequest, "Failed to create new chirp", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp", err)
 		return
 	}
 
-	resp := returnVals{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.String(),
+	err = saveChirpHashtags(r.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save hashtags", err)
+		return
 	}
-	respondWithJSON(w, http.StatusCreated, resp)
+
+	err = saveChirpMentions(r.Context(), qtx, chirp)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to save mentions", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update chirp", err)
+		return
+	}
+
+	resp, err := cfg.singleChirpResponse(r.Context(), chirp, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, resp)
 }
 
-func getCleanedBody(body string, badWords map[string]struct{}) string {
-	words := strings.Split(body, " ")
-	for i, word := range words {
-		loweredWord := strings.ToLower(word)
-		if _, ok := badWords[loweredWord]; ok {
-			words[i] = "****"
+func (cfg *apiConfig) handlerDeleteChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	// Owners can delete their chirps even when nobody else can see them,
+	// such as hidden, expired or scheduled ones.
+	chirp, err := cfg.db.GetOwnChirp(r.Context(), database.GetOwnChirpParams{
+		ID:     chirpUUID,
+		UserID: userId,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		visible, err := cfg.db.ChirpVisibleTo(r.Context(), database.ChirpVisibleToParams{
+			ChirpID:  chirpUUID,
+			ViewerID: userId,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+			return
+		}
+		if !visible {
+			respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", nil)
+			return
 		}
+		respondWithError(w, http.StatusForbidden, "Chirp belongs to another user", nil)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	err = qtx.DeleteChirp(r.Context(), database.DeleteChirpParams{
+		ID:        chirp.ID,
+		DeletedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+		return
+	}
+
+	err = qtx.DeleteChirpPins(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unpin chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateChirpBody checks the length of body and runs it through the
+// moderation rules, returning the body to store. The length is what the
+// author typed, counted as chirptext.Length does; the size limit applies to
+// the stored body, matching the constraint on the chirps table.
+func (cfg *apiConfig) validateChirpBody(body string) (string, error) {
+	if chirptext.Length(body) > cfg.maxChirpLength {
+		return "", errChirpTooLong
+	}
+
+	cleaned, err := cfg.moderator.Moderate(body)
+	if err != nil {
+		return "", err
+	}
+	if len(cleaned) > chirptext.MaxBodyBytes {
+		return "", errChirpTooLarge
+	}
+	return cleaned, nil
+}
+
+// chirpBodyError is the response message for an error from
+// validateChirpBody.
+func chirpBodyError(err error) string {
+	switch {
+	case errors.Is(err, moderation.ErrRejected):
+		return "Chirp violates the content rules"
+	case errors.Is(err, errChirpTooLarge):
+		return "Chirp is too large"
+	default:
+		return "Chirp is too long"
 	}
-	cleaned := strings.Join(words, " ")
-	return cleaned
 }
 
 func (cfg *apiConfig) handlerGetChirps(w http.ResponseWriter, r *http.Request) {
-	allChirps := []returnVals{}
+	query := r.URL.Query()
+	page, err := pagination.FromQuery(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	authorID := uuid.NullUUID{}
+	if raw := query.Get("author_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Couldn't parse author_id", err)
+			return
+		}
+		authorID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	sortDesc := false
+	switch query.Get("sort") {
+	case "", "asc":
+	case "desc":
+		sortDesc = true
+	default:
+		respondWithError(w, http.StatusBadRequest, "sort must be asc or desc", nil)
+		return
+	}
 
-	chirps, err := cfg.db.GetChirps(r.Context())
+	since, err := parseTimeParam(query.Get("since"), firstKey.CreatedAt)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse since, expected RFC 3339", err)
+		return
+	}
+
+	until, err := parseTimeParam(query.Get("until"), lastKey.CreatedAt)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse until, expected RFC 3339", err)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	var chirps []database.Chirp
+	if sortDesc {
+		before := keysetFrom(page.After, lastKey)
+		chirps, err = cfg.db.GetChirpsDesc(r.Context(), database.GetChirpsDescParams{
+			BeforeCreatedAt: before.CreatedAt,
+			BeforeID:        before.ID,
+			Since:           since,
+			Until:           until,
+			AuthorID:        authorID,
+			ViewerID:        viewerID,
+			PageLimit:       int32(page.Limit + 1),
+		})
+	} else {
+		after := keysetFrom(page.After, firstKey)
+		chirps, err = cfg.db.GetChirpsAsc(r.Context(), database.GetChirpsAscParams{
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			Since:          since,
+			Until:          until,
+			AuthorID:       authorID,
+			ViewerID:       viewerID,
+			PageLimit:      int32(page.Limit + 1),
+		})
+	}
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Failed to get chirp", err)
 		return
 	}
 
-	for _, chirp := range chirps {
-		respChirp := returnVals{
-			ID:        chirp.ID.String(),
-			CreatedAt: chirp.CreatedAt,
-			UpdatedAt: chirp.UpdatedAt,
-			Body:      chirp.Body,
-			UserID:    chirp.UserID.String(),
-		}
-		allChirps = append(allChirps, respChirp)
+	chirps, nextCursor := pageOf(chirps, page.Limit, chirpCursor)
+	allChirps, err := cfg.chirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+
+	// Clients that don't ask for pagination keep getting a bare array.
+	if !query.Has("limit") && !query.Has("cursor") {
+		respondWithJSON(w, http.StatusOK, allChirps)
+		return
 	}
 
-	respondWithJSON(w, http.StatusOK, allChirps)
+	respondWithJSON(w, http.StatusOK, chirpsPage{
+		Chirps:     allChirps,
+		NextCursor: nextCursor,
+	})
+}
+
+// parseTimeParam parses an optional RFC 3339 query value, returning def
+// when it is empty. Chirp timestamps are stored as UTC without a zone, so
+// the result is converted to UTC.
+func parseTimeParam(raw string, def time.Time) (time.Time, error) {
+	if raw == "" {
+		return def, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return t.UTC(), nil
 }
 
 func (cfg *apiConfig) handlerGetChirpById(w http.ResponseWriter, r *http.Request) {
@@ -122,18 +874,19 @@ func (cfg *apiConfig) handlerGetChirpById(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	chirp, err := cfg.db.GetChirpById(r.Context(), chirpUUID)
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: cfg.viewerID(r),
+	})
 	if err != nil {
 		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
 		return
 	}
 
-	resp := returnVals{
-		ID:        chirp.ID.String(),
-		CreatedAt: chirp.CreatedAt,
-		UpdatedAt: chirp.UpdatedAt,
-		Body:      chirp.Body,
-		UserID:    chirp.UserID.String(),
+	resp, err := cfg.singleChirpResponse(r.Context(), chirp, cfg.viewerID(r))
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
 	}
 	respondWithJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+)
+
+func (cfg *apiConfig) handlerSearchChirps(w http.ResponseWriter, r *http.Request) {
+	// Snippet is HTML: the body is escaped before matches are wrapped in
+	// <mark>, so it can be inserted into a page as is.
+	type searchResult struct {
+		returnVals
+		Snippet string `json:"snippet"`
+	}
+
+	type searchPage struct {
+		Chirps     []searchResult `json:"chirps"`
+		NextCursor string         `json:"next_cursor,omitempty"`
+	}
+
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	if q == "" {
+		respondWithError(w, http.StatusBadRequest, "q is required", nil)
+		return
+	}
+
+	page, err := pagination.OffsetFromQuery(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+	rows, err := cfg.db.SearchChirps(r.Context(), database.SearchChirpsParams{
+		Query:      q,
+		ViewerID:   viewerID,
+		PageLimit:  int32(page.Limit + 1),
+		PageOffset: int32(page.Offset),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to search chirps", err)
+		return
+	}
+
+	resp := searchPage{Chirps: []searchResult{}}
+	if len(rows) > page.Limit {
+		rows = rows[:page.Limit]
+		if next := page.Offset + page.Limit; next <= pagination.MaxOffset {
+			resp.NextCursor = pagination.EncodeOffset(next)
+		}
+	}
+
+	chirps := make([]database.Chirp, 0, len(rows))
+	for _, row := range rows {
+		chirps = append(chirps, row.Chirp)
+	}
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+
+	for i, row := range rows {
+		resp.Chirps = append(resp.Chirps, searchResult{
+			returnVals: chirpResps[i],
+			Snippet:    row.Snippet,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
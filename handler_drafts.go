@@ -0,0 +1,370 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/chirptext"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+type draftVals struct {
+	ID              string    `json:"id"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	Body            string    `json:"body"`
+	InReplyTo       string    `json:"in_reply_to,omitempty"`
+	RepostedChirpID string    `json:"reposted_chirp_id,omitempty"`
+	Visibility      string    `json:"visibility"`
+}
+
+type draftsPage struct {
+	Drafts     []draftVals `json:"drafts"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// draftParams is the body of POST /api/drafts and PUT /api/drafts/{draftID}.
+type draftParams struct {
+	Body            string `json:"body"`
+	InReplyTo       string `json:"in_reply_to"`
+	RepostedChirpID string `json:"reposted_chirp_id"`
+	Visibility      string `json:"visibility"`
+}
+
+func draftResponse(draft database.Draft) draftVals {
+	resp := draftVals{
+		ID:         draft.ID.String(),
+		CreatedAt:  draft.CreatedAt,
+		UpdatedAt:  draft.UpdatedAt,
+		Body:       draft.Body,
+		Visibility: string(draft.Visibility),
+	}
+	if draft.InReplyTo.Valid {
+		resp.InReplyTo = draft.InReplyTo.UUID.String()
+	}
+	if draft.RepostedChirpID.Valid {
+		resp.RepostedChirpID = draft.RepostedChirpID.UUID.String()
+	}
+	return resp
+}
+
+func parseOptionalUUID(raw string) (uuid.NullUUID, error) {
+	if raw == "" {
+		return uuid.NullUUID{}, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}, nil
+}
+
+// draftFields are draftParams parsed into their column types.
+type draftFields struct {
+	Body            string
+	InReplyTo       uuid.NullUUID
+	RepostedChirpID uuid.NullUUID
+	Visibility      database.ChirpVisibility
+}
+
+// maxDraftRequestBytes leaves room for a body of chirptext.MaxBodyBytes
+// even if every byte of it is escaped in the JSON, plus the other fields.
+const maxDraftRequestBytes = 6*chirptext.MaxBodyBytes + 1<<10
+
+// decodeDraft reads draftParams from the request. Only the shape and size
+// of the draft are checked here; the body is validated when it is published.
+func decodeDraft(w http.ResponseWriter, r *http.Request) (draftFields, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxDraftRequestBytes)
+	decoder := json.NewDecoder(r.Body)
+	params := draftParams{}
+	err := decoder.Decode(&params)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		respondWithError(w, http.StatusBadRequest, "Draft is too large", err)
+		return draftFields{}, false
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return draftFields{}, false
+	}
+
+	if len(params.Body) > chirptext.MaxBodyBytes {
+		respondWithError(w, http.StatusBadRequest, "Draft is too large", nil)
+		return draftFields{}, false
+	}
+
+	inReplyTo, err := parseOptionalUUID(params.InReplyTo)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse in_reply_to", err)
+		return draftFields{}, false
+	}
+
+	repostedChirpID, err := parseOptionalUUID(params.RepostedChirpID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse reposted_chirp_id", err)
+		return draftFields{}, false
+	}
+
+	visibility, err := parseVisibility(params.Visibility)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "visibility must be public, followers or mentioned", err)
+		return draftFields{}, false
+	}
+
+	return draftFields{
+		Body:            params.Body,
+		InReplyTo:       inReplyTo,
+		RepostedChirpID: repostedChirpID,
+		Visibility:      visibility,
+	}, true
+}
+
+func (cfg *apiConfig) handlerCreateDraft(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	fields, ok := decodeDraft(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.CreateDraft(r.Context(), database.CreateDraftParams{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now().UTC(),
+		UpdatedAt:       time.Now().UTC(),
+		UserID:          userId,
+		Body:            fields.Body,
+		InReplyTo:       fields.InReplyTo,
+		RepostedChirpID: fields.RepostedChirpID,
+		Visibility:      fields.Visibility,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, draftResponse(draft))
+}
+
+func (cfg *apiConfig) handlerGetDrafts(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	drafts, err := cfg.db.GetDrafts(r.Context(), database.GetDraftsParams{
+		UserID:          userId,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get drafts", err)
+		return
+	}
+
+	// Drafts are listed most recently edited first.
+	drafts, nextCursor := pageOf(drafts, page.Limit, func(draft database.Draft) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: draft.UpdatedAt, ID: draft.ID}
+	})
+	resps := []draftVals{}
+	for _, draft := range drafts {
+		resps = append(resps, draftResponse(draft))
+	}
+
+	respondWithJSON(w, http.StatusOK, draftsPage{Drafts: resps, NextCursor: nextCursor})
+}
+
+func (cfg *apiConfig) handlerGetDraft(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	draftUUID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse draft id", err)
+		return
+	}
+
+	draft, err := cfg.db.GetDraft(r.Context(), database.GetDraftParams{
+		ID:     draftUUID,
+		UserID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Draft not found", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, draftResponse(draft))
+}
+
+func (cfg *apiConfig) handlerUpdateDraft(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	draftUUID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse draft id", err)
+		return
+	}
+
+	fields, ok := decodeDraft(w, r)
+	if !ok {
+		return
+	}
+
+	draft, err := cfg.db.UpdateDraft(r.Context(), database.UpdateDraftParams{
+		ID:              draftUUID,
+		UserID:          userId,
+		Body:            fields.Body,
+		InReplyTo:       fields.InReplyTo,
+		RepostedChirpID: fields.RepostedChirpID,
+		Visibility:      fields.Visibility,
+		UpdatedAt:       time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Draft not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update draft", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, draftResponse(draft))
+}
+
+func (cfg *apiConfig) handlerDeleteDraft(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	draftUUID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse draft id", err)
+		return
+	}
+
+	deleted, err := cfg.db.DeleteDraft(r.Context(), database.DeleteDraftParams{
+		ID:     draftUUID,
+		UserID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete draft", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Draft not found", nil)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerPublishDraft(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	draftUUID, err := uuid.Parse(r.PathValue("draftID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse draft id", err)
+		return
+	}
+
+	draft, err := cfg.db.GetDraft(r.Context(), database.GetDraftParams{
+		ID:     draftUUID,
+		UserID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Draft not found", err)
+		return
+	}
+
+	params := chirpParams{
+		Body:       draft.Body,
+		Visibility: string(draft.Visibility),
+	}
+	if draft.InReplyTo.Valid {
+		params.InReplyTo = draft.InReplyTo.UUID.String()
+	}
+	if draft.RepostedChirpID.Valid {
+		params.RepostedChirpID = draft.RepostedChirpID.UUID.String()
+	}
+
+	// Publishing goes through the same validation and cleaning as a chirp
+	// posted directly, and removes the draft once the chirp is saved.
+	chirp, ok := cfg.createChirp(w, r, userId, params, draft.ID)
+	if !ok {
+		return
+	}
+
+	resp, err := cfg.singleChirpResponse(r.Context(), chirp, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get reposted chirp", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, resp)
+}
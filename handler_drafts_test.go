@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geolunalg/gochirpy/internal/chirptext"
+)
+
+func TestDecodeDraftSize(t *testing.T) {
+	draft := func(body string) string {
+		raw, _ := json.Marshal(draftParams{Body: body})
+		return string(raw)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "largest body", body: draft(strings.Repeat("a", chirptext.MaxBodyBytes)), wantCode: http.StatusOK},
+		{name: "largest escaped body", body: draft(strings.Repeat("\x01", chirptext.MaxBodyBytes)), wantCode: http.StatusOK},
+		{name: "body over the cap", body: draft(strings.Repeat("a", chirptext.MaxBodyBytes+1)), wantCode: http.StatusBadRequest},
+		{name: "request over the cap", body: `{"body": "` + strings.Repeat("a", maxDraftRequestBytes) + `"}`, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/drafts", strings.NewReader(tt.body))
+			_, ok := decodeDraft(rec, req)
+			if tt.wantCode == http.StatusOK {
+				if !ok {
+					t.Fatalf("decodeDraft() = %d: %s", rec.Code, rec.Body)
+				}
+				return
+			}
+			if ok || rec.Code != tt.wantCode {
+				t.Errorf("decodeDraft() ok = %v, code %d, want %d", ok, rec.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+type followVals struct {
+	ID         string    `json:"id"`
+	Username   string    `json:"username"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
+type followsPage struct {
+	Users      []followVals `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerFollowUser(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	followeeUUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse user id", err)
+		return
+	}
+
+	if followeeUUID == userId {
+		respondWithError(w, http.StatusBadRequest, "You can't follow yourself", nil)
+		return
+	}
+
+	followee, err := cfg.db.GetUserById(r.Context(), followeeUUID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to follow user", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	if err := lockUsers(r.Context(), qtx, userId, followee.ID); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to follow user", err)
+		return
+	}
+
+	blocked, err := qtx.IsBlockedBetween(r.Context(), database.IsBlockedBetweenParams{
+		UserA: userId,
+		UserB: followee.ID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to follow user", err)
+		return
+	}
+	if blocked {
+		respondWithError(w, http.StatusForbidden, "You can't follow this user", nil)
+		return
+	}
+
+	err = qtx.FollowUser(r.Context(), database.FollowUserParams{
+		FollowerID: userId,
+		FolloweeID: followee.ID,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to follow user", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to follow user", err)
+		return
+	}
+
+	// The follow is saved either way; if this fails its chirps are pulled
+	// when the timeline is read.
+	if err := cfg.timelines.Follow(r.Context(), userId, followee.ID); err != nil {
+		log.Printf("Failed to add %s to the timeline of %s: %s", followee.ID, userId, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnfollowUser(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	followeeUUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse user id", err)
+		return
+	}
+
+	err = cfg.db.UnfollowUser(r.Context(), database.UnfollowUserParams{
+		FollowerID: userId,
+		FolloweeID: followeeUUID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unfollow user", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerGetFollowers(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse user id", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetFollowers(r.Context(), database.GetFollowersParams{
+		UserID:          userUUID,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get followers", err)
+		return
+	}
+
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.GetFollowersRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.FollowedAt, ID: row.ID}
+	})
+	users := []followVals{}
+	for _, row := range rows {
+		users = append(users, followVals{
+			ID:         row.ID.String(),
+			Username:   row.Username,
+			FollowedAt: row.FollowedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, followsPage{Users: users, NextCursor: nextCursor})
+}
+
+func (cfg *apiConfig) handlerGetFollowing(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse user id", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetFollowing(r.Context(), database.GetFollowingParams{
+		UserID:          userUUID,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get followed users", err)
+		return
+	}
+
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.GetFollowingRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.FollowedAt, ID: row.ID}
+	})
+	users := []followVals{}
+	for _, row := range rows {
+		users = append(users, followVals{
+			ID:         row.ID.String(),
+			Username:   row.Username,
+			FollowedAt: row.FollowedAt,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, followsPage{Users: users, NextCursor: nextCursor})
+}
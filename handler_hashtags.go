@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/chirptext"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+// saveChirpHashtags replaces the hashtags linked to chirp with the ones in
+// its current body. It is called inside the transaction that writes the
+// chirp so the two never disagree.
+func saveChirpHashtags(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := q.DeleteChirpHashtags(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range chirptext.Hashtags(chirp.Body) {
+		hashtag, err := q.UpsertHashtag(ctx, database.UpsertHashtagParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			Tag:       tag,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.AddChirpHashtag(ctx, database.AddChirpHashtagParams{
+			ChirpID:   chirp.ID,
+			HashtagID: hashtag.ID,
+			CreatedAt: chirp.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cfg *apiConfig) handlerGetHashtagChirps(w http.ResponseWriter, r *http.Request) {
+	tag := strings.ToLower(strings.TrimPrefix(r.PathValue("tag"), "#"))
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	viewerID := cfg.viewerID(r)
+	chirps, err := cfg.db.GetChirpsByHashtag(r.Context(), database.GetChirpsByHashtagParams{
+		Tag:             tag,
+		ViewerID:        viewerID,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirps for hashtag", err)
+		return
+	}
+
+	chirps, nextCursor := pageOf(chirps, page.Limit, chirpCursor)
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+	resp := chirpsPage{Chirps: chirpResps, NextCursor: nextCursor}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerGetTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	type trendingTag struct {
+		Tag  string `json:"tag"`
+		Uses int64  `json:"uses"`
+	}
+
+	const (
+		defaultWindow = 24 * time.Hour
+		maxWindow     = 30 * 24 * time.Hour
+		defaultLimit  = 10
+	)
+
+	query := r.URL.Query()
+	window := defaultWindow
+	if raw := query.Get("window"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil || parsed <= 0 || parsed > maxWindow {
+			respondWithError(w, http.StatusBadRequest, "window must be a duration between 0 and 720h", err)
+			return
+		}
+		window = parsed
+	}
+
+	limit := defaultLimit
+	if raw := query.Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "limit must be a positive integer", err)
+			return
+		}
+		limit = min(parsed, pagination.MaxLimit)
+	}
+
+	// Trends are the same for everyone, so only chirps an anonymous viewer
+	// could see are counted. A scheduled chirp counts from when it was
+	// published, which is when its created_at is set.
+	rows, err := cfg.db.GetTrendingHashtags(r.Context(), database.GetTrendingHashtagsParams{
+		Since:     time.Now().UTC().Add(-window),
+		ViewerID:  uuid.Nil,
+		PageLimit: int32(limit),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get trending hashtags", err)
+		return
+	}
+
+	tags := []trendingTag{}
+	for _, row := range rows {
+		tags = append(tags, trendingTag{
+			Tag:  row.Tag,
+			Uses: row.Uses,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, tags)
+}
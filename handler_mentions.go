@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/chirptext"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+)
+
+// saveChirpMentions resolves the @usernames in the chirp body to users and
+// records them. Unknown usernames and users who block the author are
+// ignored.
+func saveChirpMentions(ctx context.Context, q *database.Queries, chirp database.Chirp) error {
+	err := q.DeleteChirpMentions(ctx, chirp.ID)
+	if err != nil {
+		return err
+	}
+
+	usernames := chirptext.Mentions(chirp.Body)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	return q.AddChirpMentions(ctx, database.AddChirpMentionsParams{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		Usernames: usernames,
+		AuthorID:  chirp.UserID,
+	})
+}
+
+func (cfg *apiConfig) handlerGetMyMentions(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	chirps, err := cfg.db.GetMentionedChirps(r.Context(), database.GetMentionedChirpsParams{
+		UserID:          userId,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get mentions", err)
+		return
+	}
+
+	chirps, nextCursor := pageOf(chirps, page.Limit, chirpCursor)
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+	resp := chirpsPage{Chirps: chirpResps, NextCursor: nextCursor}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
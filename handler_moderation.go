@@ -0,0 +1,261 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/moderation"
+	"github.com/google/uuid"
+)
+
+type moderationRuleVals struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Kind      string    `json:"kind"`
+	Action    string    `json:"action"`
+	Pattern   string    `json:"pattern"`
+}
+
+type moderationRuleParams struct {
+	Kind    string `json:"kind"`
+	Action  string `json:"action"`
+	Pattern string `json:"pattern"`
+}
+
+func moderationRuleResponse(rule database.ModerationRule) moderationRuleVals {
+	return moderationRuleVals{
+		ID:        rule.ID.String(),
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+		Kind:      rule.Kind,
+		Action:    rule.Action,
+		Pattern:   rule.Pattern,
+	}
+}
+
+// decodeModerationRule reads and validates a rule from the request body. On
+// failure it writes the error response and returns false.
+func decodeModerationRule(w http.ResponseWriter, r *http.Request) (moderation.Rule, bool) {
+	params := moderationRuleParams{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return moderation.Rule{}, false
+	}
+
+	rule := moderation.Rule{
+		Kind:    moderation.Kind(params.Kind),
+		Action:  moderation.Action(params.Action),
+		Pattern: params.Pattern,
+	}
+	if err := rule.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return moderation.Rule{}, false
+	}
+	return rule, true
+}
+
+// reloadModeration applies rule changes right away. The change is already
+// saved, so a failure here is only logged; the periodic reload retries.
+func (cfg *apiConfig) reloadModeration(r *http.Request) {
+	if err := cfg.moderator.Reload(r.Context()); err != nil {
+		log.Printf("Failed to reload moderation rules: %s", err)
+	}
+}
+
+func (cfg *apiConfig) handlerGetModerationRules(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.getAdminUser(w, r); !ok {
+		return
+	}
+
+	rules, err := cfg.db.GetModerationRules(r.Context())
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get moderation rules", err)
+		return
+	}
+
+	resp := []moderationRuleVals{}
+	for _, rule := range rules {
+		resp = append(resp, moderationRuleResponse(rule))
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// ruleDetails describes a rule in the audit log.
+func ruleDetails(rule moderation.Rule) string {
+	return fmt.Sprintf("%s %s %q", rule.Action, rule.Kind, rule.Pattern)
+}
+
+func (cfg *apiConfig) handlerCreateModerationRule(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	rule, ok := decodeModerationRule(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create moderation rule", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	created, err := qtx.CreateModerationRule(r.Context(), database.CreateModerationRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Kind:      string(rule.Kind),
+		Action:    string(rule.Action),
+		Pattern:   rule.Pattern,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Rule already exists", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create moderation rule", err)
+		return
+	}
+
+	err = logModerationAction(r.Context(), qtx, database.LogModerationActionParams{
+		ModeratorID: admin.ID,
+		Action:      "create_rule",
+		Details:     ruleDetails(rule),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create moderation rule", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to create moderation rule", err)
+		return
+	}
+
+	cfg.reloadModeration(r)
+	respondWithJSON(w, http.StatusCreated, moderationRuleResponse(created))
+}
+
+func (cfg *apiConfig) handlerUpdateModerationRule(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	ruleUUID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse rule id", err)
+		return
+	}
+
+	rule, ok := decodeModerationRule(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update moderation rule", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	updated, err := qtx.UpdateModerationRule(r.Context(), database.UpdateModerationRuleParams{
+		ID:        ruleUUID,
+		Kind:      string(rule.Kind),
+		Action:    string(rule.Action),
+		Pattern:   rule.Pattern,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusNotFound, "Rule not found", err)
+		return
+	}
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "Rule already exists", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update moderation rule", err)
+		return
+	}
+
+	err = logModerationAction(r.Context(), qtx, database.LogModerationActionParams{
+		ModeratorID: admin.ID,
+		Action:      "update_rule",
+		Details:     fmt.Sprintf("%s: %s", updated.ID, ruleDetails(rule)),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update moderation rule", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update moderation rule", err)
+		return
+	}
+
+	cfg.reloadModeration(r)
+	respondWithJSON(w, http.StatusOK, moderationRuleResponse(updated))
+}
+
+func (cfg *apiConfig) handlerDeleteModerationRule(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	ruleUUID, err := uuid.Parse(r.PathValue("ruleID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse rule id", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete moderation rule", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	deleted, err := qtx.DeleteModerationRule(r.Context(), ruleUUID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete moderation rule", err)
+		return
+	}
+	if deleted == 0 {
+		respondWithError(w, http.StatusNotFound, "Rule not found", nil)
+		return
+	}
+
+	err = logModerationAction(r.Context(), qtx, database.LogModerationActionParams{
+		ModeratorID: admin.ID,
+		Action:      "delete_rule",
+		Details:     ruleUUID.String(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete moderation rule", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to delete moderation rule", err)
+		return
+	}
+
+	cfg.reloadModeration(r)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerPinChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+
+	if chirp.UserID != userId {
+		respondWithError(w, http.StatusForbidden, "Chirp belongs to another user", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to pin chirp", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	// Locking the user serializes concurrent pins so the limit holds.
+	err = qtx.LockUser(r.Context(), userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to pin chirp", err)
+		return
+	}
+
+	// Only pins the owner can still see count, so pins left on hidden or
+	// expired chirps do not use up the limit.
+	pinned, err := qtx.GetPinnedChirpIds(r.Context(), userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to pin chirp", err)
+		return
+	}
+	if slices.Contains(pinned, chirp.ID) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if len(pinned) >= cfg.maxPins {
+		respondWithError(w, http.StatusConflict, "Too many pinned chirps", nil)
+		return
+	}
+
+	err = qtx.PinChirp(r.Context(), database.PinChirpParams{
+		UserID:    userId,
+		ChirpID:   chirp.ID,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to pin chirp", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to pin chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerUnpinChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	err = cfg.db.UnpinChirp(r.Context(), database.UnpinChirpParams{
+		UserID:  userId,
+		ChirpID: chirpUUID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to unpin chirp", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
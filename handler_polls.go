@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/moderation"
+	"github.com/google/uuid"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 50
+)
+
+// pollParams is the optional poll of a new chirp.
+type pollParams struct {
+	Options  []string  `json:"options"`
+	ClosesAt time.Time `json:"closes_at"`
+}
+
+type pollOptionVals struct {
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+	Votes int64  `json:"votes"`
+}
+
+type pollVals struct {
+	ClosesAt   time.Time        `json:"closes_at"`
+	Closed     bool             `json:"closed"`
+	TotalVotes int64            `json:"total_votes"`
+	Options    []pollOptionVals `json:"options"`
+	MyVote     string           `json:"my_vote,omitempty"`
+}
+
+// validatePollOptions cleans the option texts the same way as a chirp body
+// and checks that the poll closes after opensAt.
+func (cfg *apiConfig) validatePollOptions(poll pollParams, opensAt time.Time) ([]string, error) {
+	if len(poll.Options) < minPollOptions || len(poll.Options) > maxPollOptions {
+		return nil, fmt.Errorf("a poll needs %d to %d options", minPollOptions, maxPollOptions)
+	}
+	if !poll.ClosesAt.After(opensAt) {
+		return nil, errors.New("poll closes_at must be after the chirp is published")
+	}
+
+	options := make([]string, 0, len(poll.Options))
+	for _, option := range poll.Options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, errors.New("poll options can't be empty")
+		}
+		cleaned, err := cfg.validateChirpBody(option)
+		if errors.Is(err, moderation.ErrRejected) {
+			return nil, errors.New("poll options violate the content rules")
+		}
+		if err != nil || utf8.RuneCountInString(cleaned) > maxPollOptionLength {
+			return nil, fmt.Errorf("poll options can be at most %d characters", maxPollOptionLength)
+		}
+		options = append(options, cleaned)
+	}
+	return options, nil
+}
+
+// savePoll stores the poll of a chirp that was just created.
+func savePoll(ctx context.Context, q *database.Queries, chirp database.Chirp, closesAt time.Time, options []string) error {
+	err := q.CreatePoll(ctx, database.CreatePollParams{
+		ChirpID:   chirp.ID,
+		CreatedAt: chirp.CreatedAt,
+		ClosesAt:  closesAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, len(options))
+	for range options {
+		ids = append(ids, uuid.New())
+	}
+	return q.CreatePollOptions(ctx, database.CreatePollOptionsParams{
+		ChirpID: chirp.ID,
+		Ids:     ids,
+		Texts:   options,
+	})
+}
+
+// pollResponses loads the polls of the given chirps for viewerID in one
+// query. Chirps without a poll are absent from the result.
+func (cfg *apiConfig) pollResponses(ctx context.Context, chirpIDs []uuid.UUID, viewerID uuid.UUID) (map[uuid.UUID]*pollVals, error) {
+	rows, err := cfg.db.GetPollTallies(ctx, database.GetPollTalliesParams{
+		ViewerID: viewerID,
+		ChirpIds: chirpIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	polls := map[uuid.UUID]*pollVals{}
+	for _, row := range rows {
+		poll, ok := polls[row.ChirpID]
+		if !ok {
+			poll = &pollVals{
+				ClosesAt: row.ClosesAt,
+				Closed:   !row.ClosesAt.After(now),
+				Options:  []pollOptionVals{},
+			}
+			polls[row.ChirpID] = poll
+		}
+		poll.Options = append(poll.Options, pollOptionVals{
+			ID:    row.OptionID.String(),
+			Text:  row.Text,
+			Votes: row.Votes,
+		})
+		poll.TotalVotes += row.Votes
+		if row.VotedByMe {
+			poll.MyVote = row.OptionID.String()
+		}
+	}
+	return polls, nil
+}
+
+func (cfg *apiConfig) handlerVoteInPoll(w http.ResponseWriter, r *http.Request) {
+	type parameters struct {
+		OptionID string `json:"option_id"`
+	}
+
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	decoder := json.NewDecoder(r.Body)
+	params := parameters{}
+	err = decoder.Decode(&params)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Couldn't decode parameters", err)
+		return
+	}
+
+	optionUUID, err := uuid.Parse(params.OptionID)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse option_id", err)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+
+	poll, err := cfg.db.GetPoll(r.Context(), chirp.ID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp has no poll", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	if !poll.ClosesAt.After(now) {
+		respondWithError(w, http.StatusConflict, "Poll is closed", nil)
+		return
+	}
+
+	// The primary key on poll_votes makes a second vote a no-op, even when
+	// both arrive at once, and its foreign key rejects foreign options.
+	inserted, err := cfg.db.CastPollVote(r.Context(), database.CastPollVoteParams{
+		ChirpID:   poll.ChirpID,
+		UserID:    userId,
+		OptionID:  optionUUID,
+		CreatedAt: now,
+	})
+	if isForeignKeyViolation(err) {
+		respondWithError(w, http.StatusBadRequest, "Option is not part of this poll", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to vote", err)
+		return
+	}
+	if inserted == 0 {
+		respondWithError(w, http.StatusConflict, "Already voted in this poll", nil)
+		return
+	}
+
+	polls, err := cfg.pollResponses(r.Context(), []uuid.UUID{poll.ChirpID}, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get poll", err)
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, polls[poll.ChirpID])
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/moderation"
+	"github.com/geolunalg/gochirpy/internal/timeline"
+	"github.com/google/uuid"
+)
+
+// newTestConfig connects to the database in TEST_DB_URL, which must already
+// be migrated with goose. Tests that need it are skipped otherwise.
+func newTestConfig(t *testing.T) *apiConfig {
+	t.Helper()
+	dbURL := os.Getenv("TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	dbConn, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	queries := database.New(dbConn)
+	return &apiConfig{
+		db:             queries,
+		dbConn:         dbConn,
+		jwtSecret:      "test-secret",
+		timelines:      timeline.NewService(timeline.NewMemoryStore(100), timeline.NewDBSource(queries), 1000),
+		maxPins:        3,
+		maxChirpLength: 140,
+		moderator:      moderation.NewService(moderation.NewDBStore(queries), time.Minute),
+	}
+}
+
+// createTestUser inserts a user and returns a bearer token for them.
+func createTestUser(t *testing.T, cfg *apiConfig) string {
+	t.Helper()
+	id := uuid.New()
+	user, err := cfg.db.CreateUser(t.Context(), database.CreateUserParams{
+		ID:             id,
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+		Email:          id.String() + "@example.com",
+		HashedPassword: "unused",
+		Username:       "t_" + strings.ReplaceAll(id.String(), "-", "")[:16],
+	})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	token, err := auth.MakeJWT(user.ID, cfg.jwtSecret, time.Hour)
+	if err != nil {
+		t.Fatalf("MakeJWT() error = %v", err)
+	}
+	return token
+}
+
+func vote(cfg *apiConfig, token, chirpID, optionID string) int {
+	body := fmt.Sprintf(`{"option_id": %q}`, optionID)
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps/"+chirpID+"/poll/votes", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.SetPathValue("chirpID", chirpID)
+	rec := httptest.NewRecorder()
+	cfg.handlerVoteInPoll(rec, req)
+	return rec.Code
+}
+
+func TestPollVotesUnderConcurrency(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	const (
+		voters       = 60
+		repeatVoters = 20
+	)
+
+	author := createTestUser(t, cfg)
+	body, _ := json.Marshal(chirpParams{
+		Body: "Tabs or spaces?",
+		Poll: &pollParams{
+			Options:  []string{"tabs", "spaces", "both"},
+			ClosesAt: time.Now().Add(time.Hour),
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+author)
+	rec := httptest.NewRecorder()
+	cfg.handlerAddChirp(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/chirps = %d: %s", rec.Code, rec.Body)
+	}
+	var chirp returnVals
+	if err := json.NewDecoder(rec.Body).Decode(&chirp); err != nil {
+		t.Fatalf("decoding chirp: %v", err)
+	}
+	if chirp.Poll == nil || len(chirp.Poll.Options) != 3 {
+		t.Fatalf("chirp poll = %+v, want 3 options", chirp.Poll)
+	}
+	options := chirp.Poll.Options
+
+	tokens := make([]string, voters)
+	for i := range tokens {
+		tokens[i] = createTestUser(t, cfg)
+	}
+	repeater := createTestUser(t, cfg)
+
+	// Everyone is released at once so the inserts genuinely race.
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	codes := map[int]int{}
+	repeaterCodes := map[int]int{}
+
+	for i, token := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			code := vote(cfg, token, chirp.ID, options[i%len(options)].ID)
+			mu.Lock()
+			codes[code]++
+			mu.Unlock()
+		}()
+	}
+	for i := 0; i < repeatVoters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			code := vote(cfg, repeater, chirp.ID, options[i%len(options)].ID)
+			mu.Lock()
+			repeaterCodes[code]++
+			mu.Unlock()
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if codes[http.StatusCreated] != voters {
+		t.Errorf("distinct voters got status counts %v, want %d x 201", codes, voters)
+	}
+	if repeaterCodes[http.StatusCreated] != 1 || repeaterCodes[http.StatusConflict] != repeatVoters-1 {
+		t.Errorf("repeat voter got status counts %v, want one 201 and %d x 409", repeaterCodes, repeatVoters-1)
+	}
+
+	chirpUUID := uuid.MustParse(chirp.ID)
+	polls, err := cfg.pollResponses(t.Context(), []uuid.UUID{chirpUUID}, uuid.Nil)
+	if err != nil {
+		t.Fatalf("pollResponses() error = %v", err)
+	}
+	poll := polls[chirpUUID]
+	if poll.TotalVotes != voters+1 {
+		t.Errorf("total votes = %d, want %d", poll.TotalVotes, voters+1)
+	}
+	// Each option got an equal share of the distinct voters, and exactly one
+	// of them also holds the repeat voter's single vote.
+	share := int64(voters / len(options))
+	withRepeat := 0
+	for i, option := range poll.Options {
+		switch option.Votes {
+		case share:
+		case share + 1:
+			withRepeat++
+		default:
+			t.Errorf("option %d has %d votes, want %d or %d", i, option.Votes, share, share+1)
+		}
+	}
+	if withRepeat != 1 {
+		t.Errorf("%d options hold the repeat vote, want 1", withRepeat)
+	}
+}
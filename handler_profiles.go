@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+type profileVals struct {
+	ID           string       `json:"id"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Username     string       `json:"username"`
+	PinnedChirps []returnVals `json:"pinned_chirps"`
+	Chirps       []returnVals `json:"chirps"`
+	NextCursor   string       `json:"next_cursor,omitempty"`
+}
+
+func (cfg *apiConfig) handlerGetUserProfile(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := uuid.Parse(r.PathValue("userID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse user id", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	user, err := cfg.db.GetUserById(r.Context(), userUUID)
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "User not found", err)
+		return
+	}
+
+	viewerID := cfg.viewerID(r)
+
+	// Pins only lead the first page; later pages are plain history.
+	pinned := []database.Chirp{}
+	if page.After == nil {
+		pinned, err = cfg.db.GetPinnedChirps(r.Context(), database.GetPinnedChirpsParams{
+			UserID:   user.ID,
+			ViewerID: viewerID,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to get pinned chirps", err)
+			return
+		}
+	}
+
+	// A profile is visited on purpose, so it shows the chirps of a muted
+	// user; blocks and visibility still apply.
+	before := keysetFrom(page.After, lastKey)
+	chirps, err := cfg.db.GetUserChirps(r.Context(), database.GetUserChirpsParams{
+		UserID:          user.ID,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		ViewerID:        viewerID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirps", err)
+		return
+	}
+	chirps, nextCursor := pageOf(chirps, page.Limit, chirpCursor)
+
+	// Both lists are hydrated together so they share one set of queries.
+	resps, err := cfg.chirpResponses(r.Context(), append(pinned, chirps...), viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profileVals{
+		ID:           user.ID.String(),
+		CreatedAt:    user.CreatedAt,
+		Username:     user.Username,
+		PinnedChirps: resps[:len(pinned)],
+		Chirps:       resps[len(pinned):],
+		NextCursor:   nextCursor,
+	})
+}
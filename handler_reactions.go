@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// defaultReactions is the emoji allowlist used when REACTION_EMOJI is unset.
+const defaultReactions = "👍,❤️,😂,😮,😢,🎉"
+
+// parseReactions turns a comma-separated list of emoji into an allowlist.
+func parseReactions(raw string) map[string]struct{} {
+	allowed := map[string]struct{}{}
+	for _, emoji := range strings.Split(raw, ",") {
+		emoji = strings.TrimSpace(emoji)
+		if emoji != "" {
+			allowed[emoji] = struct{}{}
+		}
+	}
+	return allowed
+}
+
+// reactionCounts loads the reaction counts of the given chirps in one query.
+// Every chirp gets a map, empty if nobody has reacted.
+func (cfg *apiConfig) reactionCounts(ctx context.Context, chirpIDs []uuid.UUID) (map[uuid.UUID]map[string]int64, error) {
+	rows, err := cfg.db.GetChirpReactionCounts(ctx, chirpIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]map[string]int64, len(chirpIDs))
+	for _, id := range chirpIDs {
+		counts[id] = map[string]int64{}
+	}
+	for _, row := range rows {
+		counts[row.ChirpID][row.Emoji] = row.Reactions
+	}
+	return counts, nil
+}
+
+// reactionTarget authenticates the caller and checks the {chirpID} and
+// {emoji} path values of a reaction request.
+func (cfg *apiConfig) reactionTarget(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, string, bool) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	emoji := r.PathValue("emoji")
+	if _, ok := cfg.reactions[emoji]; !ok {
+		respondWithError(w, http.StatusBadRequest, "Reaction is not allowed", nil)
+		return uuid.Nil, uuid.Nil, "", false
+	}
+
+	return userId, chirpUUID, emoji, true
+}
+
+func (cfg *apiConfig) handlerAddReaction(w http.ResponseWriter, r *http.Request) {
+	userId, chirpUUID, emoji, ok := cfg.reactionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+
+	err = cfg.db.AddReaction(r.Context(), database.AddReactionParams{
+		ChirpID:   chirp.ID,
+		UserID:    userId,
+		Emoji:     emoji,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to add reaction", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (cfg *apiConfig) handlerRemoveReaction(w http.ResponseWriter, r *http.Request) {
+	userId, chirpUUID, emoji, ok := cfg.reactionTarget(w, r)
+	if !ok {
+		return
+	}
+
+	err := cfg.db.RemoveReaction(r.Context(), database.RemoveReactionParams{
+		ChirpID: chirpUUID,
+		UserID:  userId,
+		Emoji:   emoji,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to remove reaction", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
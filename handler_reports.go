@@ -0,0 +1,544 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+const maxReportReasonLength = 500
+
+// Report statuses. Open and triaged reports are pending; the others are
+// resolved.
+const (
+	reportOpen      = "open"
+	reportTriaged   = "triaged"
+	reportDismissed = "dismissed"
+	reportActioned  = "actioned"
+)
+
+// Report actions, also used as the action names in the audit log.
+const (
+	actionHideChirp     = "hide_chirp"
+	actionSuspendAuthor = "suspend_author"
+)
+
+type reportVals struct {
+	ID         string     `json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ChirpID    string     `json:"chirp_id,omitempty"`
+	ReporterID string     `json:"reporter_id"`
+	Reason     string     `json:"reason"`
+	Status     string     `json:"status"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// reportedChirpVals is the chirp as it was reported. Purged is set once the
+// chirp itself has been deleted for good.
+type reportedChirpVals struct {
+	Body   string `json:"body"`
+	UserID string `json:"user_id"`
+	Hidden bool   `json:"hidden"`
+	Purged bool   `json:"purged"`
+}
+
+// queuedReportVals is a report as shown in the moderation queue, with the
+// chirp it is about and how many reports on that chirp are still pending.
+type queuedReportVals struct {
+	reportVals
+	Chirp               reportedChirpVals `json:"chirp"`
+	OpenReportsForChirp int64             `json:"open_reports_for_chirp"`
+}
+
+type reportsPage struct {
+	Reports    []queuedReportVals `json:"reports"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type moderationActionVals struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	ModeratorID  string    `json:"moderator_id"`
+	Action       string    `json:"action"`
+	ReportID     string    `json:"report_id,omitempty"`
+	ChirpID      string    `json:"chirp_id,omitempty"`
+	TargetUserID string    `json:"target_user_id,omitempty"`
+	Details      string    `json:"details,omitempty"`
+}
+
+type moderationActionsPage struct {
+	Actions    []moderationActionVals `json:"actions"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+func reportResponse(report database.Report) reportVals {
+	resp := reportVals{
+		ID:         report.ID.String(),
+		CreatedAt:  report.CreatedAt,
+		UpdatedAt:  report.UpdatedAt,
+		ReporterID: report.ReporterID.String(),
+		Reason:     report.Reason,
+		Status:     report.Status,
+	}
+	if report.ChirpID.Valid {
+		resp.ChirpID = report.ChirpID.UUID.String()
+	}
+	if report.ResolvedBy.Valid {
+		resp.ResolvedBy = report.ResolvedBy.UUID.String()
+	}
+	if report.ResolvedAt.Valid {
+		resp.ResolvedAt = &report.ResolvedAt.Time
+	}
+	return resp
+}
+
+func nullUUID(id uuid.UUID) uuid.NullUUID {
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
+
+// logModerationAction records a moderator action in the audit log. Callers
+// run it in the same transaction as the action itself.
+func logModerationAction(ctx context.Context, q *database.Queries, params database.LogModerationActionParams) error {
+	params.ID = uuid.New()
+	params.CreatedAt = time.Now().UTC()
+	return q.LogModerationAction(ctx, params)
+}
+
+func (cfg *apiConfig) handlerReportChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	type parameters struct {
+		Reason string `json:"reason"`
+	}
+	params := parameters{}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return
+	}
+
+	reason := strings.TrimSpace(params.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxReportReasonLength {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("reason must be 1 to %d characters", maxReportReasonLength), nil)
+		return
+	}
+
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+
+	if chirp.UserID == userId {
+		respondWithError(w, http.StatusBadRequest, "You can't report your own chirp", nil)
+		return
+	}
+
+	report, err := cfg.db.CreateReport(r.Context(), database.CreateReportParams{
+		ID:            uuid.New(),
+		CreatedAt:     time.Now().UTC(),
+		UpdatedAt:     time.Now().UTC(),
+		ChirpID:       nullUUID(chirp.ID),
+		ReporterID:    userId,
+		Reason:        reason,
+		ChirpBody:     chirp.Body,
+		ChirpAuthorID: chirp.UserID,
+	})
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "You already reported this chirp", err)
+		return
+	}
+	if isForeignKeyViolation(err) {
+		respondWithError(w, http.StatusNotFound, "Chirp not found", err)
+		return
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to report chirp", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, reportResponse(report))
+}
+
+func (cfg *apiConfig) handlerGetReports(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.getAdminUser(w, r); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	status := query.Get("status")
+	switch status {
+	case "":
+		status = reportOpen
+	case reportOpen, reportTriaged, reportDismissed, reportActioned:
+	default:
+		respondWithError(w, http.StatusBadRequest, "status must be open, triaged, dismissed or actioned", nil)
+		return
+	}
+
+	page, err := pagination.FromQuery(query)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetReports(r.Context(), database.GetReportsParams{
+		Status:          status,
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get reports", err)
+		return
+	}
+
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.GetReportsRow) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.Report.CreatedAt, ID: row.Report.ID}
+	})
+	reports := []queuedReportVals{}
+	for _, row := range rows {
+		reports = append(reports, queuedReportVals{
+			reportVals: reportResponse(row.Report),
+			Chirp: reportedChirpVals{
+				Body:   row.Report.ChirpBody,
+				UserID: row.Report.ChirpAuthorID.String(),
+				Hidden: row.ChirpHiddenAt.Valid,
+				Purged: !row.Report.ChirpID.Valid,
+			},
+			OpenReportsForChirp: row.OpenReportsForChirp,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, reportsPage{Reports: reports, NextCursor: nextCursor})
+}
+
+// pendingReportError responds to a report that could not be moved on from
+// its current status: it either doesn't exist or was already handled.
+func (cfg *apiConfig) pendingReportError(w http.ResponseWriter, r *http.Request, reportID uuid.UUID, err error) {
+	if !errors.Is(err, sql.ErrNoRows) {
+		respondWithError(w, http.StatusInternalServerError, "Failed to update report", err)
+		return
+	}
+
+	report, getErr := cfg.db.GetReport(r.Context(), reportID)
+	if getErr != nil {
+		respondWithError(w, http.StatusNotFound, "Report not found", getErr)
+		return
+	}
+	respondWithError(w, http.StatusConflict, fmt.Sprintf("Report is already %s", report.Status), err)
+}
+
+func (cfg *apiConfig) handlerTriageReport(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	reportUUID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse report id", err)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to triage report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := qtx.TriageReport(r.Context(), database.TriageReportParams{
+		ID:        reportUUID,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		cfg.pendingReportError(w, r, reportUUID, err)
+		return
+	}
+
+	err = logModerationAction(r.Context(), qtx, database.LogModerationActionParams{
+		ModeratorID: admin.ID,
+		Action:      "triage_report",
+		ReportID:    nullUUID(report.ID),
+		ChirpID:     report.ChirpID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to triage report", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to triage report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reportResponse(report))
+}
+
+type resolutionParams struct {
+	Action            string `json:"action"`
+	ResolveDuplicates bool   `json:"resolve_duplicates"`
+}
+
+// decodeResolution reads the optional body of a dismiss or act request. On
+// failure it writes the error response and returns false.
+func decodeResolution(w http.ResponseWriter, r *http.Request) (resolutionParams, bool) {
+	params := resolutionParams{}
+	err := json.NewDecoder(r.Body).Decode(&params)
+	if err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Couldn't decode parameters", err)
+		return resolutionParams{}, false
+	}
+	return params, true
+}
+
+// resolveReport gives a pending report its final status and logs it. With
+// duplicates set, every other pending report on the same chirp is resolved
+// the same way.
+func resolveReport(ctx context.Context, qtx *database.Queries, moderatorID, reportID uuid.UUID, status string, duplicates bool) (database.Report, error) {
+	now := time.Now().UTC()
+	report, err := qtx.ResolveReport(ctx, database.ResolveReportParams{
+		Status:     status,
+		ResolvedBy: moderatorID,
+		Now:        now,
+		ID:         reportID,
+	})
+	if err != nil {
+		return database.Report{}, err
+	}
+
+	action := "dismiss_report"
+	if status == reportActioned {
+		action = "resolve_report"
+	}
+	err = logModerationAction(ctx, qtx, database.LogModerationActionParams{
+		ModeratorID: moderatorID,
+		Action:      action,
+		ReportID:    nullUUID(report.ID),
+		ChirpID:     report.ChirpID,
+	})
+	if err != nil || !duplicates {
+		return report, err
+	}
+
+	resolved, err := qtx.ResolveDuplicateReports(ctx, database.ResolveDuplicateReportsParams{
+		Status:     status,
+		ResolvedBy: moderatorID,
+		Now:        now,
+		ChirpID:    report.ChirpID,
+		ID:         report.ID,
+	})
+	if err != nil {
+		return database.Report{}, err
+	}
+
+	err = logModerationAction(ctx, qtx, database.LogModerationActionParams{
+		ModeratorID: moderatorID,
+		Action:      "resolve_duplicates",
+		ReportID:    nullUUID(report.ID),
+		ChirpID:     report.ChirpID,
+		Details:     fmt.Sprintf("%d other reports %s", resolved, status),
+	})
+	return report, err
+}
+
+func (cfg *apiConfig) handlerDismissReport(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	reportUUID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse report id", err)
+		return
+	}
+
+	params, ok := decodeResolution(w, r)
+	if !ok {
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to dismiss report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := resolveReport(r.Context(), qtx, admin.ID, reportUUID, reportDismissed, params.ResolveDuplicates)
+	if err != nil {
+		cfg.pendingReportError(w, r, reportUUID, err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to dismiss report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reportResponse(report))
+}
+
+// handlerActOnReport resolves a report by hiding the reported chirp or
+// suspending its author. Either way the chirp stops being visible.
+func (cfg *apiConfig) handlerActOnReport(w http.ResponseWriter, r *http.Request) {
+	admin, ok := cfg.getAdminUser(w, r)
+	if !ok {
+		return
+	}
+
+	reportUUID, err := uuid.Parse(r.PathValue("reportID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse report id", err)
+		return
+	}
+
+	params, ok := decodeResolution(w, r)
+	if !ok {
+		return
+	}
+	if params.Action != actionHideChirp && params.Action != actionSuspendAuthor {
+		respondWithError(w, http.StatusBadRequest, "action must be hide_chirp or suspend_author", nil)
+		return
+	}
+
+	tx, err := cfg.dbConn.BeginTx(r.Context(), nil)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to act on report", err)
+		return
+	}
+	defer tx.Rollback()
+	qtx := cfg.db.WithTx(tx)
+
+	report, err := resolveReport(r.Context(), qtx, admin.ID, reportUUID, reportActioned, params.ResolveDuplicates)
+	if err != nil {
+		cfg.pendingReportError(w, r, reportUUID, err)
+		return
+	}
+
+	entry := database.LogModerationActionParams{
+		ModeratorID: admin.ID,
+		Action:      params.Action,
+		ReportID:    nullUUID(report.ID),
+		ChirpID:     report.ChirpID,
+	}
+
+	switch params.Action {
+	case actionHideChirp:
+		// A purged chirp is already gone; the report is still resolved.
+		if report.ChirpID.Valid {
+			_, err = qtx.HideChirp(r.Context(), database.HideChirpParams{
+				ID:       report.ChirpID.UUID,
+				HiddenAt: time.Now().UTC(),
+			})
+		}
+	case actionSuspendAuthor:
+		entry.TargetUserID = nullUUID(report.ChirpAuthorID)
+		_, err = qtx.SuspendUser(r.Context(), database.SuspendUserParams{
+			ID:          report.ChirpAuthorID,
+			SuspendedAt: time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to act on report", err)
+		return
+	}
+
+	if err := logModerationAction(r.Context(), qtx, entry); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to act on report", err)
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to act on report", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, reportResponse(report))
+}
+
+func (cfg *apiConfig) handlerGetModerationActions(w http.ResponseWriter, r *http.Request) {
+	if _, ok := cfg.getAdminUser(w, r); !ok {
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	before := keysetFrom(page.After, lastKey)
+	rows, err := cfg.db.GetModerationActions(r.Context(), database.GetModerationActionsParams{
+		BeforeCreatedAt: before.CreatedAt,
+		BeforeID:        before.ID,
+		PageLimit:       int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get moderation actions", err)
+		return
+	}
+
+	rows, nextCursor := pageOf(rows, page.Limit, func(row database.ModerationAction) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: row.CreatedAt, ID: row.ID}
+	})
+	actions := []moderationActionVals{}
+	for _, row := range rows {
+		action := moderationActionVals{
+			ID:          row.ID.String(),
+			CreatedAt:   row.CreatedAt,
+			ModeratorID: row.ModeratorID.String(),
+			Action:      row.Action,
+			Details:     row.Details,
+		}
+		if row.ReportID.Valid {
+			action.ReportID = row.ReportID.UUID.String()
+		}
+		if row.ChirpID.Valid {
+			action.ChirpID = row.ChirpID.UUID.String()
+		}
+		if row.TargetUserID.Valid {
+			action.TargetUserID = row.TargetUserID.UUID.String()
+		}
+		actions = append(actions, action)
+	}
+
+	respondWithJSON(w, http.StatusOK, moderationActionsPage{Actions: actions, NextCursor: nextCursor})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+func doRequest(t *testing.T, handler http.HandlerFunc, method, target, token, body string, pathValues map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	for key, value := range pathValues {
+		req.SetPathValue(key, value)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestReportQueue(t *testing.T) {
+	cfg := newTestConfig(t)
+
+	author := createTestUser(t, cfg)
+	admin := createTestUser(t, cfg)
+	adminID, err := auth.ValidateJWT(admin, cfg.jwtSecret)
+	if err != nil {
+		t.Fatalf("ValidateJWT() error = %v", err)
+	}
+	if _, err := cfg.dbConn.ExecContext(t.Context(), "UPDATE users SET is_admin = TRUE WHERE id = $1", adminID); err != nil {
+		t.Fatalf("making admin: %v", err)
+	}
+
+	body, _ := json.Marshal(chirpParams{Body: "buy my stuff"})
+	req := httptest.NewRequest(http.MethodPost, "/api/chirps", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+author)
+	rec := httptest.NewRecorder()
+	cfg.handlerAddChirp(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/chirps = %d: %s", rec.Code, rec.Body)
+	}
+	var chirp returnVals
+	json.NewDecoder(rec.Body).Decode(&chirp)
+	chirpPath := map[string]string{"chirpID": chirp.ID}
+
+	if rec := doRequest(t, cfg.handlerReportChirp, http.MethodPost, "/", author, `{"reason": "spam"}`, chirpPath); rec.Code != http.StatusBadRequest {
+		t.Errorf("reporting own chirp = %d, want 400", rec.Code)
+	}
+
+	reports := []reportVals{}
+	for i := 0; i < 3; i++ {
+		reporter := createTestUser(t, cfg)
+		rec := doRequest(t, cfg.handlerReportChirp, http.MethodPost, "/", reporter, `{"reason": "spam"}`, chirpPath)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("POST reports = %d: %s", rec.Code, rec.Body)
+		}
+		var report reportVals
+		json.NewDecoder(rec.Body).Decode(&report)
+		reports = append(reports, report)
+
+		if i == 0 {
+			if rec := doRequest(t, cfg.handlerReportChirp, http.MethodPost, "/", reporter, `{"reason": "again"}`, chirpPath); rec.Code != http.StatusConflict {
+				t.Errorf("reporting twice = %d, want 409", rec.Code)
+			}
+		}
+	}
+
+	if rec := doRequest(t, cfg.handlerGetReports, http.MethodGet, "/admin/reports", author, "", nil); rec.Code != http.StatusForbidden {
+		t.Errorf("GET /admin/reports as a user = %d, want 403", rec.Code)
+	}
+
+	first := map[string]string{"reportID": reports[0].ID}
+	if rec := doRequest(t, cfg.handlerTriageReport, http.MethodPost, "/", admin, "", first); rec.Code != http.StatusOK {
+		t.Fatalf("triage = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = doRequest(t, cfg.handlerActOnReport, http.MethodPost, "/", admin, `{"action": "hide_chirp", "resolve_duplicates": true}`, first)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("act = %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, report := range reports {
+		got, err := cfg.db.GetReport(t.Context(), uuid.MustParse(report.ID))
+		if err != nil {
+			t.Fatalf("GetReport() error = %v", err)
+		}
+		if got.Status != reportActioned || got.ResolvedBy.UUID != adminID {
+			t.Errorf("report %s = %s by %v, want actioned by the admin", report.ID, got.Status, got.ResolvedBy)
+		}
+	}
+
+	second := map[string]string{"reportID": reports[1].ID}
+	if rec := doRequest(t, cfg.handlerDismissReport, http.MethodPost, "/", admin, "", second); rec.Code != http.StatusConflict {
+		t.Errorf("dismissing a resolved report = %d, want 409", rec.Code)
+	}
+
+	_, err = cfg.db.GetChirpById(t.Context(), database.GetChirpByIdParams{
+		ID:       uuid.MustParse(chirp.ID),
+		ViewerID: uuid.Nil,
+	})
+	if err == nil {
+		t.Errorf("hidden chirp is still visible")
+	}
+
+	actions, err := cfg.db.GetModerationActions(t.Context(), database.GetModerationActionsParams{PageLimit: 100})
+	if err != nil {
+		t.Fatalf("GetModerationActions() error = %v", err)
+	}
+	logged := map[string]bool{}
+	for _, action := range actions {
+		if action.ModeratorID == adminID {
+			logged[action.Action] = true
+		}
+	}
+	for _, want := range []string{"triage_report", "resolve_report", "resolve_duplicates", actionHideChirp} {
+		if !logged[want] {
+			t.Errorf("audit log is missing %s, got %v", want, logged)
+		}
+	}
+
+	// Purging the chirp keeps the reports and the copy they hold.
+	if _, err := cfg.dbConn.ExecContext(t.Context(), "DELETE FROM chirps WHERE id = $1", chirp.ID); err != nil {
+		t.Fatalf("purging chirp: %v", err)
+	}
+	got, err := cfg.db.GetReport(t.Context(), uuid.MustParse(reports[0].ID))
+	if err != nil {
+		t.Fatalf("GetReport() after purge error = %v", err)
+	}
+	if got.ChirpID.Valid || got.ChirpBody != "buy my stuff" {
+		t.Errorf("report after purge = %v %q, want no chirp id and the original body", got.ChirpID, got.ChirpBody)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerGetScheduledChirps(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	after := keysetFrom(page.After, firstKey)
+	chirps, err := cfg.db.GetScheduledChirps(r.Context(), database.GetScheduledChirpsParams{
+		UserID:         userId,
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+		PageLimit:      int32(page.Limit + 1),
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get scheduled chirps", err)
+		return
+	}
+
+	// Scheduled chirps are listed soonest first, keyed on publish_at.
+	chirps, nextCursor := pageOf(chirps, page.Limit, func(chirp database.Chirp) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: chirp.PublishAt.Time, ID: chirp.ID}
+	})
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+	resp := chirpsPage{Chirps: chirpResps, NextCursor: nextCursor}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+func (cfg *apiConfig) handlerCancelScheduledChirp(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	// A scheduled chirp was never shown to anyone, so cancelling it removes
+	// it outright rather than leaving a tombstone.
+	deleted, err := cfg.db.CancelScheduledChirp(r.Context(), database.CancelScheduledChirpParams{
+		ID:     chirpUUID,
+		UserID: userId,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to cancel scheduled chirp", err)
+		return
+	}
+	if len(deleted) == 0 {
+		respondWithError(w, http.StatusNotFound, "Scheduled chirp not found", nil)
+		return
+	}
+
+	for _, row := range deleted {
+		if row.BlobKey.Valid {
+			cfg.deleteBlobs(r.Context(), row.BlobKey.String, row.ThumbnailKey.String)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+type threadNode struct {
+	returnVals
+	Replies []*threadNode `json:"replies"`
+}
+
+func (cfg *apiConfig) handlerGetChirpThread(w http.ResponseWriter, r *http.Request) {
+	type thread struct {
+		Ancestors []returnVals  `json:"ancestors"`
+		Chirp     returnVals    `json:"chirp"`
+		Replies   []*threadNode `json:"replies"`
+	}
+
+	const (
+		maxAncestors = 100
+		defaultDepth = 3
+		maxDepth     = 10
+		maxReplies   = 500
+	)
+
+	chirpUUID, err := uuid.Parse(r.PathValue("chirpID"))
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Couldn't parse chirp id", err)
+		return
+	}
+
+	depth := defaultDepth
+	if raw := r.URL.Query().Get("depth"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "depth must be a positive integer", err)
+			return
+		}
+		depth = min(parsed, maxDepth)
+	}
+
+	viewerID := cfg.viewerID(r)
+	chirp, err := cfg.db.GetChirpById(r.Context(), database.GetChirpByIdParams{
+		ID:       chirpUUID,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusNotFound, "Failed to get chirp by id", err)
+		return
+	}
+
+	ancestors, err := cfg.db.GetChirpAncestors(r.Context(), database.GetChirpAncestorsParams{
+		ChirpID:  chirp.ID,
+		MaxDepth: maxAncestors,
+		ViewerID: viewerID,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp ancestors", err)
+		return
+	}
+
+	replies, err := cfg.db.GetChirpReplies(r.Context(), database.GetChirpRepliesParams{
+		ChirpID:    chirp.ID,
+		MaxDepth:   int32(depth),
+		ViewerID:   viewerID,
+		MaxReplies: maxReplies,
+	})
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp replies", err)
+		return
+	}
+
+	chirps := []database.Chirp{chirp}
+	for _, ancestor := range ancestors {
+		chirps = append(chirps, ancestor.Chirp)
+	}
+	for _, reply := range replies {
+		chirps = append(chirps, reply.Chirp)
+	}
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, viewerID)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+
+	resp := thread{
+		Ancestors: chirpResps[1 : 1+len(ancestors)],
+		Chirp:     chirpResps[0],
+		Replies:   []*threadNode{},
+	}
+
+	// Replies arrive ordered by depth, so a node's parent is always placed
+	// before the node itself.
+	nodes := map[uuid.UUID]*threadNode{}
+	for i, reply := range replies {
+		node := &threadNode{
+			returnVals: chirpResps[1+len(ancestors)+i],
+			Replies:    []*threadNode{},
+		}
+		nodes[reply.Chirp.ID] = node
+
+		if reply.Chirp.InReplyTo.UUID == chirp.ID {
+			resp.Replies = append(resp.Replies, node)
+			continue
+		}
+		if parent, ok := nodes[reply.Chirp.InReplyTo.UUID]; ok {
+			parent.Replies = append(parent.Replies, node)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/geolunalg/gochirpy/internal/timeline"
+	"github.com/google/uuid"
+)
+
+func (cfg *apiConfig) handlerGetTimeline(w http.ResponseWriter, r *http.Request) {
+	token, err := auth.GetBearerToken(r.Header)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	userId, err := auth.ValidateJWT(token, cfg.jwtSecret)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid token", err)
+		return
+	}
+
+	page, err := pagination.FromQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error(), err)
+		return
+	}
+
+	var before *timeline.Entry
+	if page.After != nil {
+		before = &timeline.Entry{ChirpID: page.After.ID, CreatedAt: page.After.CreatedAt}
+	}
+
+	entries, err := cfg.timelines.Read(r.Context(), userId, before, page.Limit+1)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get timeline", err)
+		return
+	}
+
+	// The cursor follows the timeline entries rather than the chirps that
+	// are returned, so entries that were filtered out don't end paging.
+	entries, nextCursor := pageOf(entries, page.Limit, func(entry timeline.Entry) pagination.Cursor {
+		return pagination.Cursor{CreatedAt: entry.CreatedAt, ID: entry.ChirpID}
+	})
+
+	ids := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ChirpID)
+	}
+
+	chirps := []database.Chirp{}
+	if len(ids) > 0 {
+		chirps, err = cfg.db.GetTimelineChirps(r.Context(), database.GetTimelineChirpsParams{
+			Ids:    ids,
+			UserID: userId,
+		})
+		if err != nil {
+			respondWithError(w, http.StatusInternalServerError, "Failed to get timeline", err)
+			return
+		}
+	}
+
+	chirpResps, err := cfg.chirpResponses(r.Context(), chirps, userId)
+	if err != nil {
+		respondWithError(w, http.StatusInternalServerError, "Failed to get chirp likes", err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, chirpsPage{Chirps: chirpResps, NextCursor: nextCursor})
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/geolunalg/gochirpy/internal/auth"
+	"github.com/geolunalg/gochirpy/internal/chirptext"
 	"github.com/geolunalg/gochirpy/internal/database"
 	"github.com/google/uuid"
 )
@@ -21,6 +23,7 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		CreatedAt    time.Time `json:"created_at"`
 		UpdatedAt    time.Time `json:"updated_at"`
 		Email        string    `json:"email"`
+		Username     string    `json:"username"`
 		Token        string    `json:"token"`
 		RefreshToken string    `json:"refresh_token"`
 	}
@@ -50,6 +53,11 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if user.SuspendedAt.Valid {
+		respondWithError(w, http.StatusForbidden, "Account is suspended", nil)
+		return
+	}
+
 	token, err := auth.MakeJWT(user.ID, cfg.jwtSecret, time.Hour)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "failed to create access token", err)
@@ -81,16 +89,23 @@ func (cfg *apiConfig) handlerLogin(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:    user.CreatedAt,
 		UpdatedAt:    user.UpdatedAt,
 		Email:        user.Email,
+		Username:     user.Username,
 		Token:        token,
 		RefreshToken: refreshToken,
 	}
 	respondWithJSON(w, http.StatusOK, resp)
 }
 
+// defaultUsername is the username given to users who sign up without one.
+func defaultUsername(id uuid.UUID) string {
+	return "user_" + strings.ReplaceAll(id.String(), "-", "")[:10]
+}
+
 func (cfg *apiConfig) handlerAddUser(w http.ResponseWriter, r *http.Request) {
 	type parameters struct {
 		Password string `json:"password"`
 		Email    string `json:"email"`
+		Username string `json:"username"`
 	}
 
 	type returnVals struct {
@@ -98,6 +113,7 @@ func (cfg *apiConfig) handlerAddUser(w http.ResponseWriter, r *http.Request) {
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
 		Email     string    `json:"email"`
+		Username  string    `json:"username"`
 	}
 
 	decoder := json.NewDecoder(r.Body)
@@ -108,19 +124,50 @@ func (cfg *apiConfig) handlerAddUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if params.Username != "" && !chirptext.ValidUsername(params.Username) {
+		respondWithError(w, http.StatusBadRequest, "Username must be 3-20 letters, digits or underscores", nil)
+		return
+	}
+
 	pwhash, err := auth.HashPassword(params.Password)
 	if err != nil {
 		respondWithError(w, http.StatusInternalServerError, "Failed to hash password", err)
 		return
 	}
 
-	user, err := cfg.db.CreateUser(r.Context(), database.CreateUserParams{
-		ID:             uuid.New(),
-		CreatedAt:      time.Now().UTC(),
-		UpdatedAt:      time.Now().UTC(),
-		Email:          params.Email,
-		HashedPassword: pwhash,
-	})
+	var user database.User
+	for attempt := 0; ; attempt++ {
+		id := uuid.New()
+		// Without a username the user gets one derived from their id, the
+		// same way existing users were given one when usernames were added.
+		username := chirptext.NormalizeUsername(params.Username)
+		if params.Username == "" {
+			username = defaultUsername(id)
+		}
+
+		user, err = cfg.db.CreateUser(r.Context(), database.CreateUserParams{
+			ID:             id,
+			CreatedAt:      time.Now().UTC(),
+			UpdatedAt:      time.Now().UTC(),
+			Email:          params.Email,
+			HashedPassword: pwhash,
+			Username:       username,
+		})
+		// A generated username that is already taken is retried with a
+		// new id rather than reported back.
+		if params.Username == "" && attempt < 2 && isUniqueViolation(err) && violatedConstraint(err) == "users_username_key" {
+			continue
+		}
+		break
+	}
+	if isUniqueViolation(err) && violatedConstraint(err) == "users_email_key" {
+		respondWithError(w, http.StatusConflict, "email taken", err)
+		return
+	}
+	if isUniqueViolation(err) {
+		respondWithError(w, http.StatusConflict, "username taken", err)
+		return
+	}
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, "Failed to create new user", err)
 		return
@@ -131,6 +178,7 @@ func (cfg *apiConfig) handlerAddUser(w http.ResponseWriter, r *http.Request) {
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 		Email:     user.Email,
+		Username:  user.Username,
 	}
 	respondWithJSON(w, http.StatusCreated, resp)
 }
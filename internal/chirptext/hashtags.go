@@ -0,0 +1,60 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+)
+
+const maxTagLength = 50
+
+// Hashtags returns the distinct #tags in body, lowercased and without the
+// leading '#', in the order they first appear. A tag must start at the
+// beginning of the body or after a non-word character and contain at
+// least one letter, so "C#" and "#2024" are not tags.
+func Hashtags(body string) []string {
+	return extractTokens(body, '#', func(tag string) (string, bool) {
+		if len(tag) > maxTagLength || !strings.ContainsFunc(tag, unicode.IsLetter) {
+			return "", false
+		}
+		return strings.ToLower(tag), true
+	})
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// extractTokens finds every word that directly follows marker and passes it
+// through accept, dropping duplicates.
+func extractTokens(body string, marker rune, accept func(string) (string, bool)) []string {
+	tokens := []string{}
+	seen := map[string]struct{}{}
+	runes := []rune(body)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != marker || (i > 0 && isWordRune(runes[i-1])) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if end == i+1 {
+			continue
+		}
+
+		token, ok := accept(string(runes[i+1 : end]))
+		i = end - 1
+		if !ok {
+			continue
+		}
+		if _, dup := seen[token]; dup {
+			continue
+		}
+		seen[token] = struct{}{}
+		tokens = append(tokens, token)
+	}
+
+	return tokens
+}
@@ -0,0 +1,64 @@
+package chirptext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "no tags",
+			body: "just a regular chirp",
+			want: []string{},
+		},
+		{
+			name: "single tag",
+			body: "loving #golang today",
+			want: []string{"golang"},
+		},
+		{
+			name: "tags are lowercased and deduplicated",
+			body: "#Go is great, #go #GO",
+			want: []string{"go"},
+		},
+		{
+			name: "trailing punctuation is not part of the tag",
+			body: "ship it #release-day!",
+			want: []string{"release"},
+		},
+		{
+			name: "tag in the middle of a word is ignored",
+			body: "I write C# and F#code",
+			want: []string{},
+		},
+		{
+			name: "numeric tags are ignored",
+			body: "#2024 was #year_of_go",
+			want: []string{"year_of_go"},
+		},
+		{
+			name: "unicode letters",
+			body: "#café #日本",
+			want: []string{"café", "日本"},
+		},
+		{
+			name: "bare hash",
+			body: "# nothing here",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hashtags(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Hashtags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package chirptext
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+)
+
+const (
+	// URLWeight is how many characters a link counts as, however long it
+	// actually is.
+	URLWeight = 23
+	// MaxBodyBytes caps the stored size of a body whatever its length in
+	// characters, since a single character can be made of any number of
+	// combining marks. The chirps table enforces the same limit.
+	MaxBodyBytes = 4096
+)
+
+// Length returns the length of body as users count it: grapheme clusters,
+// with every http:// or https:// link counting as URLWeight.
+func Length(body string) int {
+	n := 0
+	for {
+		start, end := nextURL(body)
+		if start < 0 {
+			return n + Graphemes(body)
+		}
+		n += Graphemes(body[:start]) + URLWeight
+		body = body[end:]
+	}
+}
+
+// nextURL returns the byte offsets of the first link in s, or -1, -1. A link
+// starts with a scheme that isn't glued to a preceding word and runs to the
+// next space, minus trailing punctuation.
+func nextURL(s string) (int, int) {
+	lower := strings.ToLower(s)
+	offset := 0
+	for {
+		i := strings.Index(lower[offset:], "http")
+		if i < 0 {
+			return -1, -1
+		}
+		start := offset + i
+		offset = start + len("http")
+
+		rest := lower[start:]
+		var scheme int
+		switch {
+		case strings.HasPrefix(rest, "https://"):
+			scheme = len("https://")
+		case strings.HasPrefix(rest, "http://"):
+			scheme = len("http://")
+		default:
+			continue
+		}
+		if prev, _ := utf8.DecodeLastRuneInString(s[:start]); start > 0 && isWordRune(prev) {
+			continue
+		}
+
+		end := start + scheme
+		for end < len(s) {
+			r, size := utf8.DecodeRuneInString(s[end:])
+			if unicode.IsSpace(r) {
+				break
+			}
+			end += size
+		}
+		for end > start+scheme && strings.ContainsRune(".,;:!?)]}'\"", rune(s[end-1])) {
+			end--
+		}
+		if end == start+scheme {
+			continue
+		}
+		return start, end
+	}
+}
+
+// Graphemes counts the user-perceived characters in s: extended grapheme
+// clusters as defined by Unicode Standard Annex #29.
+func Graphemes(s string) int {
+	return uniseg.GraphemeClusterCount(s)
+}
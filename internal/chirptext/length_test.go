@@ -0,0 +1,71 @@
+package chirptext
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want int
+	}{
+		{name: "empty", s: "", want: 0},
+		{name: "ascii", s: "hello", want: 5},
+		{name: "precomposed accent", s: "café", want: 4},
+		{name: "combining accent", s: "cafe\u0301", want: 4},
+		{name: "stacked combining marks", s: "Z\u0334\u0321\u0315a", want: 2},
+		{name: "crlf is one character", s: "a\r\nb", want: 3},
+		{name: "emoji", s: "👍👍👍", want: 3},
+		{name: "skin tone modifier", s: "👍🏽", want: 1},
+		{name: "variation selector", s: "❤️", want: 1},
+		{name: "zwj family", s: "👨‍👩‍👧‍👦", want: 1},
+		{name: "zwj sequence after text", s: "a‍👍", want: 2},
+		{name: "flags", s: "🇺🇸🇬🇧", want: 2},
+		{name: "odd regional indicator", s: "🇺🇸🇬", want: 2},
+		{name: "subdivision flag", s: "🏴\U000E0067\U000E0062\U000E0065\U000E006E\U000E0067\U000E007F", want: 1},
+		{name: "hangul syllables", s: "한국어", want: 3},
+		{name: "hangul jamo", s: "\u1112\u1161\u11ab", want: 1},
+		{name: "spacing mark", s: "\u0915\u093f", want: 1},
+		{name: "cjk", s: "你好，世界", want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Graphemes(tt.s); got != tt.want {
+				t.Errorf("Graphemes(%q) = %d, want %d", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLength(t *testing.T) {
+	longURL := "https://example.com/" + strings.Repeat("a", 200)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{name: "plain text", body: "hello world", want: 11},
+		{name: "fifty emoji", body: strings.Repeat("🎉", 50), want: 50},
+		{name: "url counts as fixed weight", body: "read " + longURL, want: 5 + URLWeight},
+		{name: "short url counts the same", body: "http://a.b", want: URLWeight},
+		{name: "uppercase scheme", body: "HTTPS://EXAMPLE.COM", want: URLWeight},
+		{name: "trailing punctuation is not part of the url", body: "see https://go.dev.", want: 4 + URLWeight + 1},
+		{name: "url in parentheses", body: "(https://go.dev)", want: 1 + URLWeight + 1},
+		{name: "two urls", body: "https://a.io https://b.io", want: 2*URLWeight + 1},
+		{name: "scheme without host", body: "https:// nope", want: 13},
+		{name: "scheme glued to a word", body: "xhttps://a.io", want: 13},
+		{name: "not a scheme", body: "httpd is a server", want: 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Length(tt.body); got != tt.want {
+				t.Errorf("Length(%q) = %d, want %d", tt.body, got, tt.want)
+			}
+		})
+	}
+}
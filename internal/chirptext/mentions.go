@@ -0,0 +1,38 @@
+package chirptext
+
+import "strings"
+
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 20
+)
+
+// ValidUsername reports whether name can be used as a handle: 3 to 20
+// ASCII letters, digits or underscores. Usernames are case-insensitive and
+// stored lowercased, see NormalizeUsername.
+func ValidUsername(name string) bool {
+	if len(name) < minUsernameLength || len(name) > maxUsernameLength {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+func NormalizeUsername(name string) string {
+	return strings.ToLower(name)
+}
+
+// Mentions returns the distinct, normalized usernames mentioned with
+// @username in body. Email addresses are not mistaken for mentions.
+func Mentions(body string) []string {
+	return extractTokens(body, '@', func(name string) (string, bool) {
+		if !ValidUsername(name) {
+			return "", false
+		}
+		return NormalizeUsername(name), true
+	})
+}
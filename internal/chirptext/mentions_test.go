@@ -0,0 +1,72 @@
+package chirptext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "no mentions",
+			body: "hello world",
+			want: []string{},
+		},
+		{
+			name: "single mention",
+			body: "thanks @alice!",
+			want: []string{"alice"},
+		},
+		{
+			name: "mentions are lowercased and deduplicated",
+			body: "@Bob and @bob and @carol_99",
+			want: []string{"bob", "carol_99"},
+		},
+		{
+			name: "email addresses are not mentions",
+			body: "write to bob@example.com",
+			want: []string{},
+		},
+		{
+			name: "too short or too long",
+			body: "@ab @abcdefghijklmnopqrstu",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Mentions(tt.body)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     bool
+	}{
+		{name: "simple", username: "alice", want: true},
+		{name: "mixed case and underscore", username: "Alice_99", want: true},
+		{name: "too short", username: "al", want: false},
+		{name: "too long", username: "abcdefghijklmnopqrstu", want: false},
+		{name: "punctuation", username: "alice.smith", want: false},
+		{name: "non ascii", username: "álice", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidUsername(tt.username); got != tt.want {
+				t.Errorf("ValidUsername(%q) = %v, want %v", tt.username, got, tt.want)
+			}
+		})
+	}
+}
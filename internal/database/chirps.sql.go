@@ -7,23 +7,46 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const chirpVisibleTo = `-- name: ChirpVisibleTo :one
+SELECT chirp_visible_to($1::uuid, $2::uuid)::boolean AS visible
+`
+
+type ChirpVisibleToParams struct {
+	ChirpID  uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) ChirpVisibleTo(ctx context.Context, arg ChirpVisibleToParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, chirpVisibleTo, arg.ChirpID, arg.ViewerID)
+	var visible bool
+	err := row.Scan(&visible)
+	return visible, err
+}
+
 const createChirp = `-- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, body, user_id
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector
 `
 
 type CreateChirpParams struct {
-	ID        uuid.UUID
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
-	UserID    uuid.UUID
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	InReplyTo       uuid.NullUUID
+	RepostedChirpID uuid.NullUUID
+	Visibility      ChirpVisibility
+	PublishAt       sql.NullTime
+	ExpiresAt       sql.NullTime
 }
 
 func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp, error) {
@@ -33,6 +56,11 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		arg.UpdatedAt,
 		arg.Body,
 		arg.UserID,
+		arg.InReplyTo,
+		arg.RepostedChirpID,
+		arg.Visibility,
+		arg.PublishAt,
+		arg.ExpiresAt,
 	)
 	var i Chirp
 	err := row.Scan(
@@ -41,16 +69,72 @@ func (q *Queries) CreateChirp(ctx context.Context, arg CreateChirpParams) (Chirp
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.DeletedAt,
+		&i.InReplyTo,
+		&i.RepostedChirpID,
+		&i.Visibility,
+		&i.PublishAt,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+		&i.SearchVector,
 	)
 	return i, err
 }
 
+const deleteChirp = `-- name: DeleteChirp :exec
+UPDATE chirps SET deleted_at = $2
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+type DeleteChirpParams struct {
+	ID        uuid.UUID
+	DeletedAt time.Time
+}
+
+func (q *Queries) DeleteChirp(ctx context.Context, arg DeleteChirpParams) error {
+	_, err := q.db.ExecContext(ctx, deleteChirp, arg.ID, arg.DeletedAt)
+	return err
+}
+
 const getChirpById = `-- name: GetChirpById :one
-SELECT id, created_at, updated_at, body, user_id FROM chirps WHERE id = $1
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps
+WHERE id = $1
+AND chirp_visible_to(id, $2::uuid)
+`
+
+type GetChirpByIdParams struct {
+	ID       uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirpById(ctx context.Context, arg GetChirpByIdParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpById, arg.ID, arg.ViewerID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.DeletedAt,
+		&i.InReplyTo,
+		&i.RepostedChirpID,
+		&i.Visibility,
+		&i.PublishAt,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const getChirpByIdForUpdate = `-- name: GetChirpByIdForUpdate :one
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
 `
 
-func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error) {
-	row := q.db.QueryRowContext(ctx, getChirpById, id)
+func (q *Queries) GetChirpByIdForUpdate(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getChirpByIdForUpdate, id)
 	var i Chirp
 	err := row.Scan(
 		&i.ID,
@@ -58,17 +142,53 @@ func (q *Queries) GetChirpById(ctx context.Context, id uuid.UUID) (Chirp, error)
 		&i.UpdatedAt,
 		&i.Body,
 		&i.UserID,
+		&i.DeletedAt,
+		&i.InReplyTo,
+		&i.RepostedChirpID,
+		&i.Visibility,
+		&i.PublishAt,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+		&i.SearchVector,
 	)
 	return i, err
 }
 
-const getChirps = `-- name: GetChirps :many
-SELECT id, created_at, updated_at, body, user_id FROM chirps
-ORDER BY created_at ASC
+const getChirpsAsc = `-- name: GetChirpsAsc :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps
+WHERE (created_at, id) > ($1::timestamp, $2::uuid)
+AND created_at >= $3::timestamp
+AND created_at <= $4::timestamp
+AND ($5::uuid IS NULL OR user_id = $5::uuid)
+AND chirp_visible_to(id, $6::uuid)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $6::uuid AND mutes.muted_id = chirps.user_id
+)
+ORDER BY created_at ASC, id ASC
+LIMIT $7
 `
 
-func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
-	rows, err := q.db.QueryContext(ctx, getChirps)
+type GetChirpsAscParams struct {
+	AfterCreatedAt time.Time
+	AfterID        uuid.UUID
+	Since          time.Time
+	Until          time.Time
+	AuthorID       uuid.NullUUID
+	ViewerID       uuid.UUID
+	PageLimit      int32
+}
+
+func (q *Queries) GetChirpsAsc(ctx context.Context, arg GetChirpsAscParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsAsc,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Since,
+		arg.Until,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +202,14 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 			&i.UpdatedAt,
 			&i.Body,
 			&i.UserID,
+			&i.DeletedAt,
+			&i.InReplyTo,
+			&i.RepostedChirpID,
+			&i.Visibility,
+			&i.PublishAt,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+			&i.SearchVector,
 		); err != nil {
 			return nil, err
 		}
@@ -95,3 +223,363 @@ func (q *Queries) GetChirps(ctx context.Context) ([]Chirp, error) {
 	}
 	return items, nil
 }
+
+const getChirpsByIds = `-- name: GetChirpsByIds :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps
+WHERE id = ANY($1::uuid[])
+AND chirp_visible_to(id, $2::uuid)
+`
+
+type GetChirpsByIdsParams struct {
+	Ids      []uuid.UUID
+	ViewerID uuid.UUID
+}
+
+func (q *Queries) GetChirpsByIds(ctx context.Context, arg GetChirpsByIdsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsByIds, pq.Array(arg.Ids), arg.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.DeletedAt,
+			&i.InReplyTo,
+			&i.RepostedChirpID,
+			&i.Visibility,
+			&i.PublishAt,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getChirpsDesc = `-- name: GetChirpsDesc :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps
+WHERE (created_at, id) < ($1::timestamp, $2::uuid)
+AND created_at >= $3::timestamp
+AND created_at <= $4::timestamp
+AND ($5::uuid IS NULL OR user_id = $5::uuid)
+AND chirp_visible_to(id, $6::uuid)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = $6::uuid AND mutes.muted_id = chirps.user_id
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $7
+`
+
+type GetChirpsDescParams struct {
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	Since           time.Time
+	Until           time.Time
+	AuthorID        uuid.NullUUID
+	ViewerID        uuid.UUID
+	PageLimit       int32
+}
+
+func (q *Queries) GetChirpsDesc(ctx context.Context, arg GetChirpsDescParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getChirpsDesc,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Since,
+		arg.Until,
+		arg.AuthorID,
+		arg.ViewerID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.DeletedAt,
+			&i.InReplyTo,
+			&i.RepostedChirpID,
+			&i.Visibility,
+			&i.PublishAt,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOwnChirp = `-- name: GetOwnChirp :one
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+`
+
+type GetOwnChirpParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) GetOwnChirp(ctx context.Context, arg GetOwnChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, getOwnChirp, arg.ID, arg.UserID)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.DeletedAt,
+		&i.InReplyTo,
+		&i.RepostedChirpID,
+		&i.Visibility,
+		&i.PublishAt,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const getUserChirps = `-- name: GetUserChirps :many
+SELECT id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector FROM chirps
+WHERE user_id = $1
+AND (created_at, id) < ($2::timestamp, $3::uuid)
+AND chirp_visible_to(id, $4::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT $5
+`
+
+type GetUserChirpsParams struct {
+	UserID          uuid.UUID
+	BeforeCreatedAt time.Time
+	BeforeID        uuid.UUID
+	ViewerID        uuid.UUID
+	PageLimit       int32
+}
+
+func (q *Queries) GetUserChirps(ctx context.Context, arg GetUserChirpsParams) ([]Chirp, error) {
+	rows, err := q.db.QueryContext(ctx, getUserChirps,
+		arg.UserID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.ViewerID,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Chirp
+	for rows.Next() {
+		var i Chirp
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Body,
+			&i.UserID,
+			&i.DeletedAt,
+			&i.InReplyTo,
+			&i.RepostedChirpID,
+			&i.Visibility,
+			&i.PublishAt,
+			&i.ExpiresAt,
+			&i.HiddenAt,
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const hideChirp = `-- name: HideChirp :execrows
+UPDATE chirps SET hidden_at = $2
+WHERE id = $1 AND hidden_at IS NULL
+`
+
+type HideChirpParams struct {
+	ID       uuid.UUID
+	HiddenAt time.Time
+}
+
+func (q *Queries) HideChirp(ctx context.Context, arg HideChirpParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, hideChirp, arg.ID, arg.HiddenAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreChirp = `-- name: RestoreChirp :one
+UPDATE chirps SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector
+`
+
+func (q *Queries) RestoreChirp(ctx context.Context, id uuid.UUID) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, restoreChirp, id)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.DeletedAt,
+		&i.InReplyTo,
+		&i.RepostedChirpID,
+		&i.Visibility,
+		&i.PublishAt,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const searchChirps = `-- name: SearchChirps :many
+SELECT chirps.id, chirps.created_at, chirps.updated_at, chirps.body, chirps.user_id, chirps.deleted_at, chirps.in_reply_to, chirps.reposted_chirp_id, chirps.visibility, chirps.publish_at, chirps.expires_at, chirps.hidden_at, chirps.search_vector,
+    ts_rank(chirps.search_vector, tsq)::real AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(chirps.body,
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        tsq,
+        'StartSel=<mark>, StopSel=</mark>'
+    )::text AS snippet
+FROM chirps, websearch_to_tsquery('english', $1) AS tsq
+WHERE chirps.search_vector @@ tsq
+AND chirp_visible_to(chirps.id, $2::uuid)
+ORDER BY rank DESC, chirps.id ASC
+LIMIT $3 OFFSET $4
+`
+
+type SearchChirpsParams struct {
+	Query      string
+	ViewerID   uuid.UUID
+	PageLimit  int32
+	PageOffset int32
+}
+
+type SearchChirpsRow struct {
+	Chirp   Chirp
+	Rank    float32
+	Snippet string
+}
+
+func (q *Queries) SearchChirps(ctx context.Context, arg SearchChirpsParams) ([]SearchChirpsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchChirps,
+		arg.Query,
+		arg.ViewerID,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchChirpsRow
+	for rows.Next() {
+		var i SearchChirpsRow
+		if err := rows.Scan(
+			&i.Chirp.ID,
+			&i.Chirp.CreatedAt,
+			&i.Chirp.UpdatedAt,
+			&i.Chirp.Body,
+			&i.Chirp.UserID,
+			&i.Chirp.DeletedAt,
+			&i.Chirp.InReplyTo,
+			&i.Chirp.RepostedChirpID,
+			&i.Chirp.Visibility,
+			&i.Chirp.PublishAt,
+			&i.Chirp.ExpiresAt,
+			&i.Chirp.HiddenAt,
+			&i.Chirp.SearchVector,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateChirp = `-- name: UpdateChirp :one
+UPDATE chirps SET body = $2, updated_at = $3
+WHERE id = $1
+RETURNING id, created_at, updated_at, body, user_id, deleted_at, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at, hidden_at, search_vector
+`
+
+type UpdateChirpParams struct {
+	ID        uuid.UUID
+	Body      string
+	UpdatedAt time.Time
+}
+
+func (q *Queries) UpdateChirp(ctx context.Context, arg UpdateChirpParams) (Chirp, error) {
+	row := q.db.QueryRowContext(ctx, updateChirp, arg.ID, arg.Body, arg.UpdatedAt)
+	var i Chirp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Body,
+		&i.UserID,
+		&i.DeletedAt,
+		&i.InReplyTo,
+		&i.RepostedChirpID,
+		&i.Visibility,
+		&i.PublishAt,
+		&i.ExpiresAt,
+		&i.HiddenAt,
+		&i.SearchVector,
+	)
+	return i, err
+}
@@ -6,17 +6,202 @@ package database
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type ChirpVisibility string
+
+const (
+	ChirpVisibilityPublic    ChirpVisibility = "public"
+	ChirpVisibilityFollowers ChirpVisibility = "followers"
+	ChirpVisibilityMentioned ChirpVisibility = "mentioned"
+)
+
+func (e *ChirpVisibility) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ChirpVisibility(s)
+	case string:
+		*e = ChirpVisibility(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ChirpVisibility: %T", src)
+	}
+	return nil
+}
+
+type NullChirpVisibility struct {
+	ChirpVisibility ChirpVisibility
+	Valid           bool // Valid is true if ChirpVisibility is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullChirpVisibility) Scan(value interface{}) error {
+	if value == nil {
+		ns.ChirpVisibility, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ChirpVisibility.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullChirpVisibility) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.ChirpVisibility), nil
+}
+
+type Block struct {
+	BlockerID uuid.UUID
+	BlockedID uuid.UUID
+	CreatedAt time.Time
+}
+
 type Chirp struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
+	UserID          uuid.UUID
+	DeletedAt       sql.NullTime
+	InReplyTo       uuid.NullUUID
+	RepostedChirpID uuid.NullUUID
+	Visibility      ChirpVisibility
+	PublishAt       sql.NullTime
+	ExpiresAt       sql.NullTime
+	HiddenAt        sql.NullTime
+	SearchVector    interface{}
+}
+
+type ChirpAttachment struct {
+	ID           uuid.UUID
+	ChirpID      uuid.UUID
+	Position     int32
+	CreatedAt    time.Time
+	ContentType  string
+	Width        int32
+	Height       int32
+	BlobKey      string
+	ThumbnailKey string
+}
+
+type ChirpBookmark struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpHashtag struct {
+	ChirpID   uuid.UUID
+	HashtagID uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpLike struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpMention struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpPin struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ChirpReaction struct {
+	ChirpID   uuid.UUID
+	UserID    uuid.UUID
+	Emoji     string
+	CreatedAt time.Time
+}
+
+type ChirpRevision struct {
 	ID        uuid.UUID
+	ChirpID   uuid.UUID
 	CreatedAt time.Time
-	UpdatedAt time.Time
 	Body      string
+}
+
+type Draft struct {
+	ID              uuid.UUID
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	UserID          uuid.UUID
+	Body            string
+	InReplyTo       uuid.NullUUID
+	RepostedChirpID uuid.NullUUID
+	Visibility      ChirpVisibility
+}
+
+type Follow struct {
+	FollowerID uuid.UUID
+	FolloweeID uuid.UUID
+	CreatedAt  time.Time
+	Pushed     bool
+}
+
+type Hashtag struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	Tag       string
+}
+
+type ModerationAction struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	ModeratorID  uuid.UUID
+	Action       string
+	ReportID     uuid.NullUUID
+	ChirpID      uuid.NullUUID
+	TargetUserID uuid.NullUUID
+	Details      string
+}
+
+type ModerationRule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Kind      string
+	Action    string
+	Pattern   string
+}
+
+type Mute struct {
+	MuterID   uuid.UUID
+	MutedID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type Poll struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+	ClosesAt  time.Time
+}
+
+type PollOption struct {
+	ID       uuid.UUID
+	ChirpID  uuid.UUID
+	Position int32
+	Text     string
+}
+
+type PollVote struct {
+	ChirpID   uuid.UUID
 	UserID    uuid.UUID
+	OptionID  uuid.UUID
+	CreatedAt time.Time
 }
 
 type RefreshToken struct {
@@ -28,10 +213,33 @@ type RefreshToken struct {
 	RevokedAt sql.NullTime
 }
 
+type Report struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	ChirpID       uuid.NullUUID
+	ReporterID    uuid.UUID
+	Reason        string
+	Status        string
+	ResolvedBy    uuid.NullUUID
+	ResolvedAt    sql.NullTime
+	ChirpBody     string
+	ChirpAuthorID uuid.UUID
+}
+
+type TimelineEntry struct {
+	UserID    uuid.UUID
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
 type User struct {
 	ID             uuid.UUID
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	Email          string
 	HashedPassword string
+	IsAdmin        bool
+	Username       string
+	SuspendedAt    sql.NullTime
 }
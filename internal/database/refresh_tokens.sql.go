@@ -14,12 +14,13 @@ import (
 )
 
 const getUserFromRefreshToken = `-- name: GetUserFromRefreshToken :one
-SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password FROM users
+SELECT users.id, users.created_at, users.updated_at, users.email, users.hashed_password, users.is_admin, users.username, users.suspended_at FROM users
 INNER JOIN refresh_tokens 
 ON users.id = refresh_tokens.user_id
 WHERE refresh_tokens.token = $1
 AND refresh_tokens.revoked_at IS NULL
 AND refresh_tokens.expires_at > NOW()
+AND users.suspended_at IS NULL
 `
 
 func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (User, error) {
@@ -31,6 +32,9 @@ func (q *Queries) GetUserFromRefreshToken(ctx context.Context, token string) (Us
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Username,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
@@ -13,9 +13,9 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, created_at, updated_at, email, hashed_password
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, created_at, updated_at, email, hashed_password, is_admin, username, suspended_at
 `
 
 type CreateUserParams struct {
@@ -24,6 +24,7 @@ type CreateUserParams struct {
 	UpdatedAt      time.Time
 	Email          string
 	HashedPassword string
+	Username       string
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -33,6 +34,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.UpdatedAt,
 		arg.Email,
 		arg.HashedPassword,
+		arg.Username,
 	)
 	var i User
 	err := row.Scan(
@@ -41,6 +43,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Username,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
@@ -55,7 +60,7 @@ func (q *Queries) DeleteUsers(ctx context.Context) error {
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, created_at, updated_at, email, hashed_password FROM users WHERE email = $1
+SELECT id, created_at, updated_at, email, hashed_password, is_admin, username, suspended_at FROM users WHERE email = $1
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -67,6 +72,56 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.UpdatedAt,
 		&i.Email,
 		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Username,
+		&i.SuspendedAt,
 	)
 	return i, err
 }
+
+const getUserById = `-- name: GetUserById :one
+SELECT id, created_at, updated_at, email, hashed_password, is_admin, username, suspended_at FROM users WHERE id = $1
+`
+
+func (q *Queries) GetUserById(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserById, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Email,
+		&i.HashedPassword,
+		&i.IsAdmin,
+		&i.Username,
+		&i.SuspendedAt,
+	)
+	return i, err
+}
+
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) LockUser(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockUser, id)
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :execrows
+UPDATE users SET suspended_at = $2
+WHERE id = $1 AND suspended_at IS NULL
+`
+
+type SuspendUserParams struct {
+	ID          uuid.UUID
+	SuspendedAt time.Time
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, suspendUser, arg.ID, arg.SuspendedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,236 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	// MaxImageBytes is the largest upload accepted per image.
+	MaxImageBytes = 10 << 20
+	// maxPixels guards against small files that decode to huge images. At
+	// 16 MP a decoded image and its NRGBA copy stay around 128 MB.
+	maxPixels = 16_000_000
+	// ThumbnailSize is the longest side of a thumbnail, in pixels.
+	ThumbnailSize = 320
+)
+
+var (
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image is too large")
+)
+
+// Image is an upload that has been validated and re-encoded.
+type Image struct {
+	ContentType string
+	// Ext is the file extension for ContentType, without a dot.
+	Ext    string
+	Width  int
+	Height int
+	Data   []byte
+
+	ThumbnailContentType string
+	ThumbnailExt         string
+	Thumbnail            []byte
+}
+
+// Process reads an uploaded image, checks its type by sniffing the content
+// rather than trusting the client, and re-encodes it. Re-encoding keeps only
+// the pixels, so EXIF and any other metadata is dropped; EXIF orientation is
+// not applied. Animated GIFs are reduced to their first frame.
+func Process(r io.Reader) (*Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+
+	img := &Image{ContentType: contentType, Width: cfg.Width, Height: cfg.Height}
+	var first image.Image
+	var out bytes.Buffer
+
+	switch contentType {
+	case "image/jpeg":
+		first, err = jpeg.Decode(bytes.NewReader(data))
+		if err == nil {
+			err = jpeg.Encode(&out, first, &jpeg.Options{Quality: 90})
+		}
+		img.Ext = "jpg"
+	case "image/png":
+		first, err = png.Decode(bytes.NewReader(data))
+		if err == nil {
+			err = png.Encode(&out, first)
+		}
+		img.Ext = "png"
+	case "image/gif":
+		// Only the first frame is decoded and kept. maxPixels bounds a
+		// single frame, but a small file can hold enough frames to exhaust
+		// memory when all of them are decoded.
+		first, err = gif.Decode(bytes.NewReader(data))
+		if err == nil {
+			err = gif.Encode(&out, first, nil)
+		}
+		img.Ext = "gif"
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, err)
+	}
+	img.Data = out.Bytes()
+
+	// Photos stay JPEG; everything else may have transparency, so PNG.
+	var thumb bytes.Buffer
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&thumb, Thumbnail(first, ThumbnailSize), &jpeg.Options{Quality: 80})
+		img.ThumbnailContentType, img.ThumbnailExt = "image/jpeg", "jpg"
+	} else {
+		err = png.Encode(&thumb, Thumbnail(first, ThumbnailSize))
+		img.ThumbnailContentType, img.ThumbnailExt = "image/png", "png"
+	}
+	if err != nil {
+		return nil, err
+	}
+	img.Thumbnail = thumb.Bytes()
+
+	return img, nil
+}
+
+// Thumbnail scales src down so that neither side exceeds maxSide, keeping
+// the aspect ratio. Each output pixel is the average of the source pixels it
+// covers. Images that already fit are copied unscaled.
+func Thumbnail(src image.Image, maxSide int) *image.NRGBA {
+	pix := toNRGBA(src)
+	w, h := pix.Rect.Dx(), pix.Rect.Dy()
+	tw, th := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			tw, th = maxSide, max(1, h*maxSide/w)
+		} else {
+			tw, th = max(1, w*maxSide/h), maxSide
+		}
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0 := y * h / th
+		y1 := max(y0+1, (y+1)*h/th)
+		for x := 0; x < tw; x++ {
+			x0 := x * w / tw
+			x1 := max(x0+1, (x+1)*w/tw)
+
+			var r, g, b, a uint64
+			for sy := y0; sy < y1; sy++ {
+				row := pix.Pix[sy*pix.Stride+x0*4 : sy*pix.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					r += uint64(row[i])
+					g += uint64(row[i+1])
+					b += uint64(row[i+2])
+					a += uint64(row[i+3])
+				}
+			}
+			n := uint64((y1 - y0) * (x1 - x0))
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// toNRGBA returns src as an NRGBA image whose bounds start at the origin.
+// The types the standard decoders produce are converted by reading their
+// pixel buffers directly; going through At for every pixel allocates and is
+// several times slower on large uploads.
+func toNRGBA(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	if img, ok := src.(*image.NRGBA); ok && b.Min == (image.Point{}) {
+		return img
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	switch img := src.(type) {
+	case *image.NRGBA:
+		for y := 0; y < b.Dy(); y++ {
+			i := img.PixOffset(b.Min.X, b.Min.Y+y)
+			copy(dst.Pix[y*dst.Stride:(y+1)*dst.Stride], img.Pix[i:])
+		}
+	case *image.RGBA:
+		for y := 0; y < b.Dy(); y++ {
+			s := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+			d := dst.Pix[y*dst.Stride : (y+1)*dst.Stride]
+			for i := 0; i < len(d); i += 4 {
+				r, g, bl, a := s[i], s[i+1], s[i+2], s[i+3]
+				if a != 0 && a != 0xff {
+					r = uint8(uint32(r) * 0xff / uint32(a))
+					g = uint8(uint32(g) * 0xff / uint32(a))
+					bl = uint8(uint32(bl) * 0xff / uint32(a))
+				}
+				d[i], d[i+1], d[i+2], d[i+3] = r, g, bl, a
+			}
+		}
+	case *image.YCbCr:
+		for y := 0; y < b.Dy(); y++ {
+			d := dst.Pix[y*dst.Stride : (y+1)*dst.Stride]
+			for x := 0; x < b.Dx(); x++ {
+				yi := img.YOffset(b.Min.X+x, b.Min.Y+y)
+				ci := img.COffset(b.Min.X+x, b.Min.Y+y)
+				r, g, bl := color.YCbCrToRGB(img.Y[yi], img.Cb[ci], img.Cr[ci])
+				d[x*4], d[x*4+1], d[x*4+2], d[x*4+3] = r, g, bl, 0xff
+			}
+		}
+	case *image.Paletted:
+		palette := make([]color.NRGBA, 256)
+		for i, c := range img.Palette {
+			palette[i] = color.NRGBAModel.Convert(c).(color.NRGBA)
+		}
+		for y := 0; y < b.Dy(); y++ {
+			s := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+			d := dst.Pix[y*dst.Stride : (y+1)*dst.Stride]
+			for x := 0; x < b.Dx(); x++ {
+				c := palette[s[x]]
+				d[x*4], d[x*4+1], d[x*4+2], d[x*4+3] = c.R, c.G, c.B, c.A
+			}
+		}
+	case *image.Gray:
+		for y := 0; y < b.Dy(); y++ {
+			s := img.Pix[img.PixOffset(b.Min.X, b.Min.Y+y):]
+			d := dst.Pix[y*dst.Stride : (y+1)*dst.Stride]
+			for x := 0; x < b.Dx(); x++ {
+				d[x*4], d[x*4+1], d[x*4+2], d[x*4+3] = s[x], s[x], s[x], 0xff
+			}
+		}
+	default:
+		for y := 0; y < b.Dy(); y++ {
+			for x := 0; x < b.Dx(); x++ {
+				dst.Set(x, y, src.At(b.Min.X+x, b.Min.Y+y))
+			}
+		}
+	}
+	return dst
+}
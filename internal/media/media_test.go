@@ -0,0 +1,296 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// animatedGIF returns a GIF with the given number of w x h frames.
+func animatedGIF(t *testing.T, w, h, frames int) []byte {
+	t.Helper()
+	anim := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+		frame.SetColorIndex(0, 0, uint8(i))
+		anim.Image = append(anim.Image, frame)
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("gif.EncodeAll() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// jpegWithExif returns a JPEG whose first segment is an APP1 EXIF block.
+func jpegWithExif(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 30), nil); err != nil {
+		t.Fatalf("jpeg.Encode() error = %v", err)
+	}
+	data := buf.Bytes()
+
+	payload := []byte("Exif\x00\x00GPS 51.5007N 0.1246W")
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...) // SOI
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestProcess(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		wantType     string
+		wantW, wantH int
+		wantThumbW   int
+		wantFrames   int
+		wantErr      error
+	}{
+		{
+			name:       "png",
+			data:       encodePNG(t, testImage(640, 480)),
+			wantType:   "image/png",
+			wantW:      640,
+			wantH:      480,
+			wantThumbW: ThumbnailSize,
+		},
+		{
+			name:       "jpeg with exif",
+			data:       jpegWithExif(t),
+			wantType:   "image/jpeg",
+			wantW:      40,
+			wantH:      30,
+			wantThumbW: 40,
+		},
+		{
+			name:       "animated gif",
+			data:       animatedGIF(t, 64, 48, 3),
+			wantType:   "image/gif",
+			wantW:      64,
+			wantH:      48,
+			wantThumbW: 64,
+			wantFrames: 1,
+		},
+		{
+			name:    "text claiming to be an image",
+			data:    []byte("definitely a png, trust me"),
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name:    "truncated png",
+			data:    encodePNG(t, testImage(64, 64))[:60],
+			wantErr: ErrUnsupportedType,
+		},
+		{
+			name:    "too large",
+			data:    append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, MaxImageBytes)...),
+			wantErr: ErrTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Process(bytes.NewReader(tt.data))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Process() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Process() error = %v", err)
+			}
+
+			if img.ContentType != tt.wantType || img.Width != tt.wantW || img.Height != tt.wantH {
+				t.Errorf("Process() = %s %dx%d, want %s %dx%d",
+					img.ContentType, img.Width, img.Height, tt.wantType, tt.wantW, tt.wantH)
+			}
+			if bytes.Contains(img.Data, []byte("Exif")) || bytes.Contains(img.Data, []byte("GPS")) {
+				t.Errorf("Process() kept metadata in the re-encoded image")
+			}
+
+			if tt.wantFrames > 0 {
+				anim, err := gif.DecodeAll(bytes.NewReader(img.Data))
+				if err != nil {
+					t.Fatalf("decoding gif: %v", err)
+				}
+				if len(anim.Image) != tt.wantFrames {
+					t.Errorf("gif has %d frames, want %d", len(anim.Image), tt.wantFrames)
+				}
+			}
+
+			thumb, _, err := image.DecodeConfig(bytes.NewReader(img.Thumbnail))
+			if err != nil {
+				t.Fatalf("decoding thumbnail: %v", err)
+			}
+			if thumb.Width != tt.wantThumbW {
+				t.Errorf("thumbnail width = %d, want %d", thumb.Width, tt.wantThumbW)
+			}
+		})
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	tests := []struct {
+		w, h, max    int
+		wantW, wantH int
+	}{
+		{w: 1000, h: 500, max: 100, wantW: 100, wantH: 50},
+		{w: 500, h: 1000, max: 100, wantW: 50, wantH: 100},
+		{w: 80, h: 60, max: 100, wantW: 80, wantH: 60},
+		{w: 3000, h: 2, max: 100, wantW: 100, wantH: 1},
+	}
+
+	for _, tt := range tests {
+		got := Thumbnail(testImage(tt.w, tt.h), tt.max).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("Thumbnail(%dx%d, %d) = %dx%d, want %dx%d",
+				tt.w, tt.h, tt.max, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestThumbnailAveragesPixels(t *testing.T) {
+	// A 2x1 black and white image scaled to 1x1 should come out mid grey.
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{A: 255})
+	src.SetNRGBA(1, 0, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+
+	got := Thumbnail(src, 1).NRGBAAt(0, 0)
+	if got.R < 126 || got.R > 128 || got.A != 255 {
+		t.Errorf("Thumbnail() pixel = %v, want mid grey", got)
+	}
+}
+
+func TestToNRGBAMatchesGenericConversion(t *testing.T) {
+	src := testImage(37, 23)
+	offset := image.Rect(5, 7, 42, 30)
+
+	rgba := image.NewRGBA(offset)
+	ycbcr := image.NewYCbCr(offset, image.YCbCrSubsampleRatio420)
+	paletted := image.NewPaletted(offset, palette.Plan9)
+	gray := image.NewGray(offset)
+	for y := 0; y < 23; y++ {
+		for x := 0; x < 37; x++ {
+			c := src.NRGBAAt(x, y)
+			c.A = uint8(x * 7)
+			rgba.Set(offset.Min.X+x, offset.Min.Y+y, c)
+			yy, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+			ycbcr.Y[ycbcr.YOffset(offset.Min.X+x, offset.Min.Y+y)] = yy
+			ci := ycbcr.COffset(offset.Min.X+x, offset.Min.Y+y)
+			ycbcr.Cb[ci], ycbcr.Cr[ci] = cb, cr
+			paletted.Set(offset.Min.X+x, offset.Min.Y+y, c)
+			gray.Set(offset.Min.X+x, offset.Min.Y+y, c)
+		}
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"nrgba", src},
+		{"nrgba subimage", src.SubImage(image.Rect(3, 2, 30, 20))},
+		{"rgba", rgba},
+		{"ycbcr", ycbcr},
+		{"paletted", paletted},
+		{"gray", gray},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toNRGBA(tt.img)
+			b := tt.img.Bounds()
+			if got.Rect != image.Rect(0, 0, b.Dx(), b.Dy()) {
+				t.Fatalf("toNRGBA() bounds = %v, want %dx%d at the origin", got.Rect, b.Dx(), b.Dy())
+			}
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+					if c := got.NRGBAAt(x, y); !closeNRGBA(c, want) {
+						t.Fatalf("toNRGBA() pixel (%d, %d) = %v, want %v", x, y, c, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// closeNRGBA allows for rounding differences of one step per channel.
+func closeNRGBA(a, b color.NRGBA) bool {
+	near := func(x, y uint8) bool { return x-y <= 1 || y-x <= 1 }
+	return near(a.R, b.R) && near(a.G, b.G) && near(a.B, b.B) && a.A == b.A
+}
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	if err := store.Put(ctx, "abc_thumb.png", strings.NewReader("pixels")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	f, err := store.Open(ctx, "abc_thumb.png")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	got, _ := io.ReadAll(f)
+	f.Close()
+	if string(got) != "pixels" {
+		t.Errorf("Open() content = %q, want %q", got, "pixels")
+	}
+
+	if err := store.Delete(ctx, "abc_thumb.png"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Open(ctx, "abc_thumb.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "abc_thumb.png"); err != nil {
+		t.Errorf("Delete() of a missing blob error = %v", err)
+	}
+
+	for _, key := range []string{"../secret.png", "a/b.png", "", ".hidden", "noext"} {
+		if err := store.Put(ctx, key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Open(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Open(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package moderation
+
+import (
+	"context"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+)
+
+// DBStore reads rules from the moderation_rules table.
+type DBStore struct {
+	db *database.Queries
+}
+
+func NewDBStore(db *database.Queries) *DBStore {
+	return &DBStore{db: db}
+}
+
+func (s *DBStore) Rules(ctx context.Context) ([]Rule, error) {
+	rows, err := s.db.GetModerationRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, Rule{
+			Kind:    Kind(row.Kind),
+			Action:  Action(row.Action),
+			Pattern: row.Pattern,
+		})
+	}
+	return rules, nil
+}
@@ -0,0 +1,247 @@
+package moderation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrRejected is returned by a Moderator that refuses a body outright.
+var ErrRejected = errors.New("content is not allowed")
+
+// mask replaces masked words and regex matches.
+const mask = "****"
+
+// Moderator checks a chirp body and returns it, possibly rewritten. A body
+// that can't be published at all is refused with an error wrapping
+// ErrRejected.
+type Moderator interface {
+	Moderate(body string) (string, error)
+}
+
+// Chain runs moderators in order, each on the output of the previous one,
+// and stops at the first error.
+type Chain []Moderator
+
+func (c Chain) Moderate(body string) (string, error) {
+	for _, m := range c {
+		var err error
+		body, err = m.Moderate(body)
+		if err != nil {
+			return "", err
+		}
+	}
+	return body, nil
+}
+
+// isWordRune reports whether r belongs to a word. Combining marks are
+// included so that decomposed accented letters stay in one word.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.IsMark(r)
+}
+
+// words calls fn with the byte offsets of each word in body. Anything that
+// is not a letter, number or mark separates words, so "Kerfuffle!" and
+// "kerfuffle's" both contain the word "kerfuffle".
+func words(body string, fn func(start, end int)) {
+	start := -1
+	for i, r := range body {
+		switch {
+		case isWordRune(r) && start < 0:
+			start = i
+		case !isWordRune(r) && start >= 0:
+			fn(start, i)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		fn(start, len(body))
+	}
+}
+
+// normalizeWord is how words are compared: case-insensitively.
+func normalizeWord(word string) string {
+	return strings.ToLower(word)
+}
+
+// isSingleWord reports whether s is exactly one word.
+func isSingleWord(s string) bool {
+	if s == "" || !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if !isWordRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// WordMask replaces listed words with asterisks, keeping the punctuation
+// around them.
+type WordMask struct {
+	words map[string]struct{}
+}
+
+func NewWordMask(list ...string) WordMask {
+	m := WordMask{words: map[string]struct{}{}}
+	for _, word := range list {
+		m.words[normalizeWord(word)] = struct{}{}
+	}
+	return m
+}
+
+func (m WordMask) Moderate(body string) (string, error) {
+	if len(m.words) == 0 {
+		return body, nil
+	}
+
+	var b strings.Builder
+	last := 0
+	words(body, func(start, end int) {
+		if _, ok := m.words[normalizeWord(body[start:end])]; ok {
+			b.WriteString(body[last:start])
+			b.WriteString(mask)
+			last = end
+		}
+	})
+	b.WriteString(body[last:])
+	return b.String(), nil
+}
+
+// RegexMask replaces every match of its patterns with asterisks.
+type RegexMask struct {
+	patterns []*regexp.Regexp
+}
+
+func NewRegexMask(patterns ...*regexp.Regexp) RegexMask {
+	return RegexMask{patterns: patterns}
+}
+
+func (m RegexMask) Moderate(body string) (string, error) {
+	for _, re := range m.patterns {
+		body = re.ReplaceAllLiteralString(body, mask)
+	}
+	return body, nil
+}
+
+// Reject refuses bodies containing any of its words or matching any of its
+// patterns.
+type Reject struct {
+	words    map[string]struct{}
+	patterns []*regexp.Regexp
+}
+
+func NewReject(list []string, patterns []*regexp.Regexp) Reject {
+	r := Reject{words: map[string]struct{}{}, patterns: patterns}
+	for _, word := range list {
+		r.words[normalizeWord(word)] = struct{}{}
+	}
+	return r
+}
+
+func (r Reject) Moderate(body string) (string, error) {
+	rejected := false
+	words(body, func(start, end int) {
+		if _, ok := r.words[normalizeWord(body[start:end])]; ok {
+			rejected = true
+		}
+	})
+	for _, re := range r.patterns {
+		if rejected {
+			break
+		}
+		rejected = re.MatchString(body)
+	}
+	if rejected {
+		return "", ErrRejected
+	}
+	return body, nil
+}
+
+// Kind is how a rule's pattern is matched.
+type Kind string
+
+const (
+	KindWord  Kind = "word"
+	KindRegex Kind = "regex"
+)
+
+// Action is what happens to a body that matches a rule.
+type Action string
+
+const (
+	ActionMask   Action = "mask"
+	ActionReject Action = "reject"
+)
+
+// Rule is one configurable moderation rule.
+type Rule struct {
+	Kind    Kind
+	Action  Action
+	Pattern string
+}
+
+// Validate checks that the rule can be built: word patterns must be a
+// single word and regex patterns must compile.
+func (r Rule) Validate() error {
+	switch r.Action {
+	case ActionMask, ActionReject:
+	default:
+		return fmt.Errorf("action must be %s or %s", ActionMask, ActionReject)
+	}
+
+	switch r.Kind {
+	case KindWord:
+		if !isSingleWord(r.Pattern) {
+			return errors.New("word rules must be a single word without spaces or punctuation")
+		}
+	case KindRegex:
+		if r.Pattern == "" {
+			return errors.New("regex rules can't be empty")
+		}
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid regex: %w", err)
+		}
+		if re.MatchString("") {
+			return errors.New("regex rules must not match an empty string")
+		}
+	default:
+		return fmt.Errorf("kind must be %s or %s", KindWord, KindRegex)
+	}
+	return nil
+}
+
+// Build turns rules into a chain. Reject rules run first, on the body as
+// written, then words are masked, then regex matches.
+func Build(rules []Rule) (Chain, error) {
+	var maskWords, rejectWords []string
+	var maskPatterns, rejectPatterns []*regexp.Regexp
+
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Pattern, err)
+		}
+
+		switch {
+		case rule.Kind == KindWord && rule.Action == ActionMask:
+			maskWords = append(maskWords, rule.Pattern)
+		case rule.Kind == KindWord:
+			rejectWords = append(rejectWords, rule.Pattern)
+		case rule.Action == ActionMask:
+			maskPatterns = append(maskPatterns, regexp.MustCompile(rule.Pattern))
+		default:
+			rejectPatterns = append(rejectPatterns, regexp.MustCompile(rule.Pattern))
+		}
+	}
+
+	return Chain{
+		NewReject(rejectWords, rejectPatterns),
+		NewWordMask(maskWords...),
+		NewRegexMask(maskPatterns...),
+	}, nil
+}
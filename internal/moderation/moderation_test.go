@@ -0,0 +1,158 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWordMask(t *testing.T) {
+	m := NewWordMask("kerfuffle", "sharbert", "Fornax", "çà")
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "clean body",
+			body: "I had something interesting for breakfast",
+			want: "I had something interesting for breakfast",
+		},
+		{
+			name: "words are matched case-insensitively",
+			body: "I hear Mastodon is better than Chirpy. sharbert I need to migrate",
+			want: "I hear Mastodon is better than Chirpy. **** I need to migrate",
+		},
+		{
+			name: "trailing punctuation is kept",
+			body: "What a Kerfuffle!",
+			want: "What a ****!",
+		},
+		{
+			name: "surrounding punctuation and apostrophes",
+			body: "(fornax), \"SHARBERT\" and kerfuffle's end",
+			want: "(****), \"****\" and ****'s end",
+		},
+		{
+			name: "words inside other words are kept",
+			body: "kerfuffled sharberts",
+			want: "kerfuffled sharberts",
+		},
+		{
+			name: "unicode punctuation and letters",
+			body: "bad kerfuffle。ÇÀ!",
+			want: "bad ****。****!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Moderate(tt.body)
+			if err != nil {
+				t.Fatalf("Moderate() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Moderate(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuild(t *testing.T) {
+	chain, err := Build([]Rule{
+		{Kind: KindWord, Action: ActionMask, Pattern: "kerfuffle"},
+		{Kind: KindRegex, Action: ActionMask, Pattern: `\b\d{3}-\d{4}\b`},
+		{Kind: KindWord, Action: ActionReject, Pattern: "spamword"},
+		{Kind: KindRegex, Action: ActionReject, Pattern: `(?i)buy\s+now`},
+	})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	tests := []struct {
+		body    string
+		want    string
+		wantErr error
+	}{
+		{body: "call 555-1234, kerfuffle!", want: "call ****, ****!"},
+		{body: "Totally not SpamWord.", wantErr: ErrRejected},
+		{body: "BUY   now while stocks last", wantErr: ErrRejected},
+		{body: "buying nowhere", want: "buying nowhere"},
+	}
+
+	for _, tt := range tests {
+		got, err := chain.Moderate(tt.body)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Moderate(%q) error = %v, want %v", tt.body, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Moderate(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		rule    Rule
+		wantErr bool
+	}{
+		{rule: Rule{Kind: KindWord, Action: ActionMask, Pattern: "kerfuffle"}},
+		{rule: Rule{Kind: KindRegex, Action: ActionReject, Pattern: `a+b`}},
+		{rule: Rule{Kind: KindWord, Action: ActionMask, Pattern: "two words"}, wantErr: true},
+		{rule: Rule{Kind: KindWord, Action: ActionMask, Pattern: "bang!"}, wantErr: true},
+		{rule: Rule{Kind: KindWord, Action: ActionMask, Pattern: ""}, wantErr: true},
+		{rule: Rule{Kind: KindRegex, Action: ActionMask, Pattern: `(`}, wantErr: true},
+		{rule: Rule{Kind: KindRegex, Action: ActionMask, Pattern: `x*`}, wantErr: true},
+		{rule: Rule{Kind: "glob", Action: ActionMask, Pattern: "x"}, wantErr: true},
+		{rule: Rule{Kind: KindWord, Action: "delete", Pattern: "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.rule.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+		}
+	}
+}
+
+type fakeStore struct {
+	rules []Rule
+	err   error
+}
+
+func (f *fakeStore) Rules(ctx context.Context) ([]Rule, error) {
+	return f.rules, f.err
+}
+
+func TestServiceReload(t *testing.T) {
+	ctx := context.Background()
+	store := &fakeStore{}
+	service := NewService(store, time.Minute)
+
+	if got, _ := service.Moderate("kerfuffle"); got != "kerfuffle" {
+		t.Errorf("Moderate() before Reload() = %q, want it unchanged", got)
+	}
+
+	store.rules = []Rule{{Kind: KindWord, Action: ActionMask, Pattern: "kerfuffle"}}
+	if err := service.Reload(ctx); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got, _ := service.Moderate("kerfuffle"); got != "****" {
+		t.Errorf("Moderate() after Reload() = %q, want %q", got, "****")
+	}
+
+	// Broken rules or a failing store keep the previous rules in effect.
+	store.rules = []Rule{{Kind: KindRegex, Action: ActionMask, Pattern: "("}}
+	if err := service.Reload(ctx); err == nil {
+		t.Errorf("Reload() with an invalid rule error = nil")
+	}
+	store.err = errors.New("db down")
+	if err := service.Reload(ctx); err == nil {
+		t.Errorf("Reload() with a failing store error = nil")
+	}
+	if got, _ := service.Moderate("kerfuffle"); got != "****" {
+		t.Errorf("Moderate() after failed Reload() = %q, want %q", got, "****")
+	}
+}
@@ -0,0 +1,68 @@
+package moderation
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// Store loads the configured rules.
+type Store interface {
+	Rules(ctx context.Context) ([]Rule, error)
+}
+
+// Service moderates bodies with the rules last loaded from its store. Rules
+// can be reloaded at any time without blocking moderation.
+type Service struct {
+	store    Store
+	interval time.Duration
+	current  atomic.Pointer[Chain]
+}
+
+// NewService returns a service that allows everything until the first
+// Reload. Run reloads every interval, which picks up rules changed by other
+// processes.
+func NewService(store Store, interval time.Duration) *Service {
+	s := &Service{store: store, interval: interval}
+	s.current.Store(&Chain{})
+	return s
+}
+
+func (s *Service) Moderate(body string) (string, error) {
+	return s.current.Load().Moderate(body)
+}
+
+// Reload loads the rules from the store. If they can't be loaded or built,
+// the previous rules stay in effect.
+func (s *Service) Reload(ctx context.Context) error {
+	rules, err := s.store.Rules(ctx)
+	if err != nil {
+		return err
+	}
+
+	chain, err := Build(rules)
+	if err != nil {
+		return err
+	}
+	s.current.Store(&chain)
+	return nil
+}
+
+// Run reloads the rules every interval until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Reload(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to reload moderation rules: %s", err)
+		}
+	}
+}
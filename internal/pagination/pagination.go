@@ -0,0 +1,141 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+	// MaxOffset is how deep offset-based pages go. Deeper pages are slow to
+	// compute and rarely useful for ranked results.
+	MaxOffset = 10_000
+)
+
+// Cursor is a keyset position on (created_at, id). It is handed to clients
+// as an opaque string.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type Params struct {
+	Limit int
+	After *Cursor
+}
+
+// OffsetParams is used for result sets without a stable keyset, such as
+// search results ordered by rank.
+type OffsetParams struct {
+	Limit  int
+	Offset int
+}
+
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(s string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("malformed cursor: %v", err)
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return Cursor{}, fmt.Errorf("malformed cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("malformed cursor: %v", err)
+	}
+
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("malformed cursor: %v", err)
+	}
+
+	return Cursor{CreatedAt: t, ID: u}, nil
+}
+
+// FromQuery reads the limit and cursor query parameters. A missing limit
+// falls back to DefaultLimit and larger values are capped at MaxLimit.
+func FromQuery(query url.Values) (Params, error) {
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		return Params{}, err
+	}
+	params := Params{Limit: limit}
+
+	if raw := query.Get("cursor"); raw != "" {
+		cursor, err := DecodeCursor(raw)
+		if err != nil {
+			return Params{}, err
+		}
+		params.After = &cursor
+	}
+
+	return params, nil
+}
+
+func EncodeOffset(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func DecodeOffset(s string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, fmt.Errorf("malformed cursor: %v", err)
+	}
+
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("malformed cursor")
+	}
+	if offset > MaxOffset {
+		return 0, fmt.Errorf("cursor is past the last page")
+	}
+
+	return offset, nil
+}
+
+// OffsetFromQuery is FromQuery for offset-based pages.
+func OffsetFromQuery(query url.Values) (OffsetParams, error) {
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		return OffsetParams{}, err
+	}
+	params := OffsetParams{Limit: limit}
+
+	if raw := query.Get("cursor"); raw != "" {
+		offset, err := DecodeOffset(raw)
+		if err != nil {
+			return OffsetParams{}, err
+		}
+		params.Offset = offset
+	}
+
+	return params, nil
+}
+
+func parseLimit(raw string) (int, error) {
+	if raw == "" {
+		return DefaultLimit, nil
+	}
+
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("limit must be a positive integer")
+	}
+
+	return min(limit, MaxLimit), nil
+}
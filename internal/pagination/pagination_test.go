@@ -0,0 +1,151 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2025, 3, 14, 15, 9, 26, 535897000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	got, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(cursor.CreatedAt) || got.ID != cursor.ID {
+		t.Errorf("DecodeCursor() = %v, want %v", got, cursor)
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Now().UTC(), ID: uuid.New()}
+
+	tests := []struct {
+		name      string
+		query     url.Values
+		wantLimit int
+		wantAfter bool
+		wantErr   bool
+	}{
+		{
+			name:      "no parameters",
+			query:     url.Values{},
+			wantLimit: DefaultLimit,
+		},
+		{
+			name:      "explicit limit",
+			query:     url.Values{"limit": {"10"}},
+			wantLimit: 10,
+		},
+		{
+			name:      "limit above max",
+			query:     url.Values{"limit": {"1000"}},
+			wantLimit: MaxLimit,
+		},
+		{
+			name:    "zero limit",
+			query:   url.Values{"limit": {"0"}},
+			wantErr: true,
+		},
+		{
+			name:    "non numeric limit",
+			query:   url.Values{"limit": {"ten"}},
+			wantErr: true,
+		},
+		{
+			name:      "valid cursor",
+			query:     url.Values{"cursor": {cursor.Encode()}},
+			wantLimit: DefaultLimit,
+			wantAfter: true,
+		},
+		{
+			name:    "garbage cursor",
+			query:   url.Values{"cursor": {"not-a-cursor"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := FromQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("FromQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if params.Limit != tt.wantLimit {
+				t.Errorf("FromQuery() limit = %d, want %d", params.Limit, tt.wantLimit)
+			}
+			if (params.After != nil) != tt.wantAfter {
+				t.Errorf("FromQuery() after = %v, wantAfter %v", params.After, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestOffsetFromQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{
+			name:      "no parameters",
+			query:     url.Values{},
+			wantLimit: DefaultLimit,
+		},
+		{
+			name:       "offset cursor",
+			query:      url.Values{"limit": {"20"}, "cursor": {EncodeOffset(40)}},
+			wantLimit:  20,
+			wantOffset: 40,
+		},
+		{
+			name:       "deepest offset",
+			query:      url.Values{"cursor": {EncodeOffset(MaxOffset)}},
+			wantLimit:  DefaultLimit,
+			wantOffset: MaxOffset,
+		},
+		{
+			name:    "offset past the last page",
+			query:   url.Values{"cursor": {EncodeOffset(MaxOffset + 1)}},
+			wantErr: true,
+		},
+		{
+			name:    "offset overflowing int32",
+			query:   url.Values{"cursor": {EncodeOffset(1 << 40)}},
+			wantErr: true,
+		},
+		{
+			name:    "keyset cursor is rejected",
+			query:   url.Values{"cursor": {Cursor{CreatedAt: time.Now(), ID: uuid.New()}.Encode()}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := OffsetFromQuery(tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("OffsetFromQuery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if params.Limit != tt.wantLimit || params.Offset != tt.wantOffset {
+				t.Errorf("OffsetFromQuery() = %+v, want limit %d offset %d", params, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+)
+
+// DBStore claims due chirps with SELECT ... FOR UPDATE SKIP LOCKED, so
+// replicas polling at the same time never publish a chirp twice.
+type DBStore struct {
+	db *database.Queries
+}
+
+func NewDBStore(db *database.Queries) *DBStore {
+	return &DBStore{db: db}
+}
+
+func (s *DBStore) PublishDue(ctx context.Context, now time.Time, limit int) ([]database.Chirp, error) {
+	return s.db.PublishDueChirps(ctx, database.PublishDueChirpsParams{
+		Now:       now,
+		BatchSize: int32(limit),
+	})
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+)
+
+// Store claims scheduled chirps that are due. Claiming must be safe when
+// several workers, possibly in different processes, poll at once: each due
+// chirp is returned to exactly one of them.
+type Store interface {
+	// PublishDue marks up to limit chirps scheduled at or before now as
+	// published and returns them.
+	PublishDue(ctx context.Context, now time.Time, limit int) ([]database.Chirp, error)
+}
+
+// Worker periodically publishes scheduled chirps whose time has come.
+type Worker struct {
+	store     Store
+	interval  time.Duration
+	batchSize int
+	onPublish func(context.Context, database.Chirp)
+	now       func() time.Time
+}
+
+// NewWorker returns a worker that polls store every interval. onPublish is
+// called for each chirp once it is published, e.g. to fan it out to
+// timelines.
+func NewWorker(store Store, interval time.Duration, batchSize int, onPublish func(context.Context, database.Chirp)) *Worker {
+	return &Worker{
+		store:     store,
+		interval:  interval,
+		batchSize: batchSize,
+		onPublish: onPublish,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run publishes due chirps until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.PublishDue(ctx); err != nil {
+			log.Printf("Failed to publish scheduled chirps: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue publishes every chirp that is due, batch by batch, and returns
+// how many were published. Cancelling ctx stops it after the current batch.
+func (w *Worker) PublishDue(ctx context.Context) (int, error) {
+	// A claimed batch is always handed to onPublish in full, even during
+	// shutdown, so no published chirp misses its timelines.
+	batchCtx := context.WithoutCancel(ctx)
+
+	published := 0
+	for {
+		chirps, err := w.store.PublishDue(batchCtx, w.now(), w.batchSize)
+		if err != nil {
+			return published, err
+		}
+
+		for _, chirp := range chirps {
+			w.onPublish(batchCtx, chirp)
+		}
+		published += len(chirps)
+
+		if len(chirps) < w.batchSize || ctx.Err() != nil {
+			return published, nil
+		}
+	}
+}
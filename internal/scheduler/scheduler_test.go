@@ -0,0 +1,203 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// fakeStore hands out each due chirp once, like the SKIP LOCKED query.
+type fakeStore struct {
+	mu        sync.Mutex
+	scheduled []database.Chirp
+	calls     int
+	err       error
+}
+
+func (f *fakeStore) schedule(publishAt time.Time) database.Chirp {
+	chirp := database.Chirp{ID: uuid.New(), CreatedAt: publishAt}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.scheduled = append(f.scheduled, chirp)
+	return chirp
+}
+
+func (f *fakeStore) PublishDue(ctx context.Context, now time.Time, limit int) ([]database.Chirp, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	sort.Slice(f.scheduled, func(i, j int) bool {
+		return f.scheduled[i].CreatedAt.Before(f.scheduled[j].CreatedAt)
+	})
+	due := []database.Chirp{}
+	rest := []database.Chirp{}
+	for _, chirp := range f.scheduled {
+		if !chirp.CreatedAt.After(now) && len(due) < limit {
+			due = append(due, chirp)
+		} else {
+			rest = append(rest, chirp)
+		}
+	}
+	f.scheduled = rest
+	return due, nil
+}
+
+type recorder struct {
+	mu        sync.Mutex
+	published []uuid.UUID
+}
+
+func (r *recorder) onPublish(ctx context.Context, chirp database.Chirp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.published = append(r.published, chirp.ID)
+}
+
+func (r *recorder) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.published)
+}
+
+func TestPublishDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		offsets   []time.Duration
+		batchSize int
+		wantCount int
+		wantCalls int
+	}{
+		{
+			name:      "nothing scheduled",
+			batchSize: 10,
+			wantCount: 0,
+			wantCalls: 1,
+		},
+		{
+			name:      "only past chirps are published",
+			offsets:   []time.Duration{-time.Minute, 0, time.Minute, time.Hour},
+			batchSize: 10,
+			wantCount: 2,
+			wantCalls: 1,
+		},
+		{
+			name:      "backlog is drained in batches",
+			offsets:   []time.Duration{-5 * time.Minute, -4 * time.Minute, -3 * time.Minute, -2 * time.Minute, -time.Minute},
+			batchSize: 2,
+			wantCount: 5,
+			wantCalls: 3,
+		},
+		{
+			name:      "full last batch needs one more poll",
+			offsets:   []time.Duration{-2 * time.Minute, -time.Minute},
+			batchSize: 2,
+			wantCount: 2,
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{}
+			for _, offset := range tt.offsets {
+				store.schedule(now.Add(offset))
+			}
+			rec := &recorder{}
+			w := NewWorker(store, time.Minute, tt.batchSize, rec.onPublish)
+			w.now = func() time.Time { return now }
+
+			got, err := w.PublishDue(context.Background())
+			if err != nil {
+				t.Fatalf("PublishDue() error = %v", err)
+			}
+			if got != tt.wantCount || rec.count() != tt.wantCount {
+				t.Errorf("PublishDue() = %d, onPublish called %d times, want %d", got, rec.count(), tt.wantCount)
+			}
+			if store.calls != tt.wantCalls {
+				t.Errorf("store polled %d times, want %d", store.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestPublishDueError(t *testing.T) {
+	store := &fakeStore{err: errors.New("connection refused")}
+	rec := &recorder{}
+	w := NewWorker(store, time.Minute, 10, rec.onPublish)
+
+	if _, err := w.PublishDue(context.Background()); err == nil {
+		t.Fatal("PublishDue() error = nil, want error")
+	}
+	if rec.count() != 0 {
+		t.Errorf("onPublish called %d times, want 0", rec.count())
+	}
+}
+
+func TestPublishDueCancelledFinishesBatch(t *testing.T) {
+	store := &fakeStore{}
+	for i := 0; i < 4; i++ {
+		store.schedule(time.Now().UTC().Add(-time.Minute))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var gotErr error
+	w := NewWorker(store, time.Minute, 2, func(ctx context.Context, chirp database.Chirp) {
+		gotErr = ctx.Err()
+	})
+
+	got, err := w.PublishDue(ctx)
+	if err != nil {
+		t.Fatalf("PublishDue() error = %v", err)
+	}
+	if got != 2 {
+		t.Errorf("PublishDue() = %d, want the 2 chirps of the claimed batch", got)
+	}
+	if gotErr != nil {
+		t.Errorf("onPublish got a cancelled context: %v", gotErr)
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	store := &fakeStore{}
+	rec := &recorder{}
+	w := NewWorker(store, 5*time.Millisecond, 10, rec.onPublish)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.Run(ctx)
+		close(done)
+	}()
+
+	// A chirp scheduled while the worker runs is picked up on a later tick.
+	store.schedule(time.Now().UTC())
+	deadline := time.After(time.Second)
+	for rec.count() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("scheduled chirp was never published")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
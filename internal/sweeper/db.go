@@ -0,0 +1,50 @@
+package sweeper
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/media"
+	"github.com/google/uuid"
+)
+
+// DBStore deletes expired chirps from the chirps table. Likes, bookmarks
+// and other rows that reference a chirp go with it through ON DELETE CASCADE;
+// reports keep their own copy of the chirp. Attachment blobs are removed
+// from blobs once the rows are gone.
+type DBStore struct {
+	db    *database.Queries
+	blobs media.BlobStore
+}
+
+func NewDBStore(db *database.Queries, blobs media.BlobStore) *DBStore {
+	return &DBStore{db: db, blobs: blobs}
+}
+
+func (s *DBStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	rows, err := s.db.DeleteExpiredChirps(ctx, database.DeleteExpiredChirpsParams{
+		Now:       now,
+		BatchSize: int32(limit),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := map[uuid.UUID]bool{}
+	for _, row := range rows {
+		deleted[row.ID] = true
+		for _, key := range []string{row.BlobKey.String, row.ThumbnailKey.String} {
+			if key == "" {
+				continue
+			}
+			// The chirp is already gone, so a failure only leaves an
+			// unreferenced file behind.
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				log.Printf("Failed to delete blob %s: %s", key, err)
+			}
+		}
+	}
+	return int64(len(deleted)), nil
+}
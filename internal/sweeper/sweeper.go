@@ -0,0 +1,70 @@
+package sweeper
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Store hard-deletes expired chirps together with their attachments and
+// everything else that belongs to them.
+type Store interface {
+	// DeleteExpired deletes up to limit chirps that expired at or before now
+	// and returns how many were deleted.
+	DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error)
+}
+
+// Sweeper periodically purges expired chirps. Read paths already hide a
+// chirp once it expires; the sweeper only reclaims the rows.
+type Sweeper struct {
+	store     Store
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+func New(store Store, interval time.Duration, batchSize int) *Sweeper {
+	return &Sweeper{
+		store:     store,
+		interval:  interval,
+		batchSize: batchSize,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (s *Sweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Failed to purge expired chirps: %s", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes every chirp that has expired, batch by batch, and returns
+// how many were deleted.
+func (s *Sweeper) Sweep(ctx context.Context) (int64, error) {
+	now := s.now()
+
+	var deleted int64
+	for {
+		n, err := s.store.DeleteExpired(ctx, now, s.batchSize)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+
+		if n < int64(s.batchSize) || ctx.Err() != nil {
+			return deleted, nil
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeStore struct {
+	mu        sync.Mutex
+	expiresAt []time.Time
+	calls     int
+	failAfter int
+}
+
+func (f *fakeStore) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.failAfter > 0 && f.calls > f.failAfter {
+		return 0, errors.New("connection reset")
+	}
+
+	var deleted int64
+	kept := []time.Time{}
+	for _, expiresAt := range f.expiresAt {
+		if !expiresAt.After(now) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, expiresAt)
+	}
+	f.expiresAt = kept
+	return deleted, nil
+}
+
+func (f *fakeStore) remaining() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.expiresAt)
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		offsets       []time.Duration
+		batchSize     int
+		failAfter     int
+		wantDeleted   int64
+		wantRemaining int
+		wantCalls     int
+		wantErr       bool
+	}{
+		{
+			name:          "nothing expired",
+			offsets:       []time.Duration{time.Minute, time.Hour},
+			batchSize:     10,
+			wantDeleted:   0,
+			wantRemaining: 2,
+			wantCalls:     1,
+		},
+		{
+			name:          "expired at exactly now is purged",
+			offsets:       []time.Duration{-time.Hour, 0, time.Second},
+			batchSize:     10,
+			wantDeleted:   2,
+			wantRemaining: 1,
+			wantCalls:     1,
+		},
+		{
+			name:          "backlog is purged in batches",
+			offsets:       []time.Duration{-5 * time.Minute, -4 * time.Minute, -3 * time.Minute, -2 * time.Minute, -time.Minute},
+			batchSize:     2,
+			wantDeleted:   5,
+			wantRemaining: 0,
+			wantCalls:     3,
+		},
+		{
+			name:          "error stops the sweep",
+			offsets:       []time.Duration{-3 * time.Minute, -2 * time.Minute, -time.Minute},
+			batchSize:     1,
+			failAfter:     1,
+			wantDeleted:   1,
+			wantRemaining: 2,
+			wantCalls:     2,
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{failAfter: tt.failAfter}
+			for _, offset := range tt.offsets {
+				store.expiresAt = append(store.expiresAt, now.Add(offset))
+			}
+			s := New(store, time.Minute, tt.batchSize)
+			s.now = func() time.Time { return now }
+
+			deleted, err := s.Sweep(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Sweep() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("Sweep() = %d, want %d", deleted, tt.wantDeleted)
+			}
+			if store.remaining() != tt.wantRemaining {
+				t.Errorf("%d chirps remain, want %d", store.remaining(), tt.wantRemaining)
+			}
+			if store.calls != tt.wantCalls {
+				t.Errorf("store called %d times, want %d", store.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSweepUsesOneCutoff(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{}
+	for i := 1; i <= 4; i++ {
+		store.expiresAt = append(store.expiresAt, start.Add(time.Duration(i)*time.Second))
+	}
+
+	// The clock moves on while batches run, but chirps that expire during
+	// a sweep are left for the next one.
+	s := New(store, time.Minute, 1)
+	clock := start
+	s.now = func() time.Time {
+		clock = clock.Add(2 * time.Second)
+		return clock
+	}
+
+	deleted, err := s.Sweep(context.Background())
+	if err != nil {
+		t.Fatalf("Sweep() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Sweep() = %d, want 2", deleted)
+	}
+}
+
+func TestRunSweepsUntilCancelled(t *testing.T) {
+	store := &fakeStore{}
+	s := New(store, 5*time.Millisecond, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	// A chirp that expires while the sweeper runs is purged on a later tick.
+	store.mu.Lock()
+	store.expiresAt = append(store.expiresAt, time.Now().UTC())
+	store.mu.Unlock()
+
+	deadline := time.After(time.Second)
+	for store.remaining() > 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expired chirp was never purged")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
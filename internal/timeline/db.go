@@ -0,0 +1,135 @@
+package timeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/google/uuid"
+)
+
+// DBStore keeps timelines in the timeline_entries table.
+type DBStore struct {
+	db *database.Queries
+}
+
+func NewDBStore(db *database.Queries) *DBStore {
+	return &DBStore{db: db}
+}
+
+func (s *DBStore) Push(ctx context.Context, userIDs []uuid.UUID, entry Entry) error {
+	return s.db.AddTimelineEntries(ctx, database.AddTimelineEntriesParams{
+		UserIds:   userIDs,
+		ChirpID:   entry.ChirpID,
+		CreatedAt: entry.CreatedAt,
+	})
+}
+
+func (s *DBStore) Backfill(ctx context.Context, userID uuid.UUID, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	chirpIDs := make([]uuid.UUID, 0, len(entries))
+	for _, entry := range entries {
+		chirpIDs = append(chirpIDs, entry.ChirpID)
+	}
+	return s.db.BackfillTimelineEntries(ctx, database.BackfillTimelineEntriesParams{
+		UserID:   userID,
+		ChirpIds: chirpIDs,
+	})
+}
+
+func (s *DBStore) Read(ctx context.Context, userID uuid.UUID, before *Entry, limit int) ([]Entry, error) {
+	key := keyBefore(before)
+	rows, err := s.db.GetTimelineEntries(ctx, database.GetTimelineEntriesParams{
+		UserID:          userID,
+		BeforeCreatedAt: key.CreatedAt,
+		BeforeID:        key.ChirpID,
+		PageLimit:       int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, Entry{ChirpID: row.ChirpID, CreatedAt: row.CreatedAt})
+	}
+	return entries, nil
+}
+
+// DBSource reads follows and chirps straight from the database.
+type DBSource struct {
+	db *database.Queries
+}
+
+func NewDBSource(db *database.Queries) *DBSource {
+	return &DBSource{db: db}
+}
+
+func (s *DBSource) FollowerCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.db.CountFollowers(ctx, userID)
+}
+
+func (s *DBSource) PushedFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return s.db.GetPushedFollowerIds(ctx, userID)
+}
+
+func (s *DBSource) SetPushed(ctx context.Context, followerID, followeeID uuid.UUID, pushed bool) error {
+	return s.db.SetFollowPushed(ctx, database.SetFollowPushedParams{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		Pushed:     pushed,
+	})
+}
+
+func (s *DBSource) Unpush(ctx context.Context, userID uuid.UUID) error {
+	return s.db.UnpushFollowers(ctx, userID)
+}
+
+func (s *DBSource) Chirps(ctx context.Context, userID uuid.UUID, limit int) ([]Entry, error) {
+	rows, err := s.db.GetAuthorTimelineEntries(ctx, database.GetAuthorTimelineEntriesParams{
+		UserID:    userID,
+		PageLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, Entry{ChirpID: row.ID, CreatedAt: row.CreatedAt})
+	}
+	return entries, nil
+}
+
+func (s *DBSource) Pull(ctx context.Context, userID uuid.UUID, all bool, before *Entry, limit int) ([]Entry, error) {
+	key := keyBefore(before)
+	rows, err := s.db.GetPulledTimelineEntries(ctx, database.GetPulledTimelineEntriesParams{
+		UserID:          userID,
+		AllFollows:      all,
+		BeforeCreatedAt: key.CreatedAt,
+		BeforeID:        key.ChirpID,
+		PageLimit:       int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(rows))
+	for _, row := range rows {
+		entries = append(entries, Entry{ChirpID: row.ID, CreatedAt: row.CreatedAt})
+	}
+	return entries, nil
+}
+
+// lastEntry sorts after every real timeline entry, so reading before it
+// starts from the newest chirp.
+var lastEntry = Entry{ChirpID: uuid.Max, CreatedAt: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC)}
+
+func keyBefore(before *Entry) Entry {
+	if before == nil {
+		return lastEntry
+	}
+	return *before
+}
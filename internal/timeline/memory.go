@@ -0,0 +1,86 @@
+package timeline
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore keeps the newest entries of each timeline in process. It is
+// lost on restart and not shared between replicas, so it only suits a single
+// instance; Service pulls from its Source while a timeline is empty.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	timelines map[uuid.UUID][]Entry
+	maxLen    int
+}
+
+func NewMemoryStore(maxLen int) *MemoryStore {
+	return &MemoryStore{
+		timelines: map[uuid.UUID][]Entry{},
+		maxLen:    maxLen,
+	}
+}
+
+func (m *MemoryStore) Push(ctx context.Context, userIDs []uuid.UUID, entry Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, userID := range userIDs {
+		m.insert(userID, entry)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Backfill(ctx context.Context, userID uuid.UUID, entries []Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, entry := range entries {
+		m.insert(userID, entry)
+	}
+	return nil
+}
+
+// insert adds entry to the user's timeline in order, dropping the oldest
+// entry when it is full. The caller holds m.mu.
+func (m *MemoryStore) insert(userID uuid.UUID, entry Entry) {
+	entries := m.timelines[userID]
+	i := sort.Search(len(entries), func(i int) bool {
+		return !newer(entries[i], entry)
+	})
+	if i < len(entries) && entries[i].ChirpID == entry.ChirpID {
+		return
+	}
+	if i >= m.maxLen {
+		return
+	}
+
+	entries = append(entries, Entry{})
+	copy(entries[i+1:], entries[i:])
+	entries[i] = entry
+	if len(entries) > m.maxLen {
+		entries = entries[:m.maxLen]
+	}
+	m.timelines[userID] = entries
+}
+
+func (m *MemoryStore) Read(ctx context.Context, userID uuid.UUID, before *Entry, limit int) ([]Entry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := m.timelines[userID]
+	start := 0
+	if before != nil {
+		start = sort.Search(len(entries), func(i int) bool {
+			return newer(*before, entries[i])
+		})
+	}
+	end := min(start+limit, len(entries))
+
+	out := make([]Entry, end-start)
+	copy(out, entries[start:end])
+	return out, nil
+}
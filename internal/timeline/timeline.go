@@ -0,0 +1,197 @@
+package timeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is a chirp as it appears in a home timeline. Timelines are ordered
+// newest first on (CreatedAt, ChirpID).
+type Entry struct {
+	ChirpID   uuid.UUID
+	CreatedAt time.Time
+}
+
+// Store holds precomputed home timelines that chirps are pushed into when
+// they are written.
+type Store interface {
+	// Push adds entry to the timeline of every user in userIDs.
+	Push(ctx context.Context, userIDs []uuid.UUID, entry Entry) error
+	// Backfill adds entries to the timeline of userID.
+	Backfill(ctx context.Context, userID uuid.UUID, entries []Entry) error
+	// Read returns up to limit entries of the user's timeline that come
+	// after before, or from the newest entry when before is nil.
+	Read(ctx context.Context, userID uuid.UUID, before *Entry, limit int) ([]Entry, error)
+}
+
+// Source is the system of record the service falls back to. It also records
+// for every follow whether it is pushed, i.e. whether the followee's chirps
+// are written into the follower's timeline or pulled when it is read.
+type Source interface {
+	FollowerCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	// PushedFollowerIDs returns the followers of userID whose follow is
+	// pushed.
+	PushedFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	SetPushed(ctx context.Context, followerID, followeeID uuid.UUID, pushed bool) error
+	// Unpush switches every follower of userID to pulling its chirps.
+	Unpush(ctx context.Context, userID uuid.UUID) error
+	// Chirps returns up to limit of the user's newest chirps.
+	Chirps(ctx context.Context, userID uuid.UUID, limit int) ([]Entry, error)
+	// Pull returns the user's own chirps and those of the accounts they
+	// follow without pushing. With all set it pulls every followed account.
+	Pull(ctx context.Context, userID uuid.UUID, all bool, before *Entry, limit int) ([]Entry, error)
+}
+
+// Service builds home timelines with fan-out-on-write for most accounts.
+// Chirps by accounts with more than threshold followers are not pushed to
+// every follower but merged in when a timeline is read. The choice is made
+// once per follow and kept on the follow, so every replica reads a timeline
+// the same way.
+type Service struct {
+	store     Store
+	source    Source
+	threshold int64
+	// backfillLimit is the most chirps copied into a timeline on follow.
+	// Accounts with more are pulled instead, so a timeline never holds part
+	// of an account's history. It equals the threshold, so a follow writes
+	// no more entries than publishing one chirp may.
+	backfillLimit int
+}
+
+func NewService(store Store, source Source, threshold int64) *Service {
+	return &Service{
+		store:         store,
+		source:        source,
+		threshold:     threshold,
+		backfillLimit: int(min(max(threshold, 0), math.MaxInt32-1)),
+	}
+}
+
+// Follow decides how followerID's new follow of followeeID is served. If
+// the followee is small enough to push, its existing chirps are copied into
+// the follower's timeline first.
+func (s *Service) Follow(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	count, err := s.source.FollowerCount(ctx, followeeID)
+	if err != nil {
+		return err
+	}
+	if count > s.threshold {
+		return nil
+	}
+
+	// The follow is marked before the backfill so chirps published in
+	// between are pushed rather than lost.
+	if err := s.source.SetPushed(ctx, followerID, followeeID, true); err != nil {
+		return err
+	}
+
+	entries, err := s.source.Chirps(ctx, followeeID, s.backfillLimit+1)
+	if err == nil && len(entries) > s.backfillLimit {
+		return s.source.SetPushed(ctx, followerID, followeeID, false)
+	}
+	if err == nil {
+		err = s.store.Backfill(ctx, followerID, entries)
+	}
+	if err != nil {
+		// Pulling is always complete, so fall back to it.
+		if unsetErr := s.source.SetPushed(ctx, followerID, followeeID, false); unsetErr != nil {
+			return errors.Join(err, unsetErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// Publish pushes a new chirp by authorID to the followers whose follow is
+// pushed. Once the author has too many followers for that to be cheap, all
+// its follows switch to pulling, which needs no backfill.
+func (s *Service) Publish(ctx context.Context, authorID uuid.UUID, entry Entry) error {
+	count, err := s.source.FollowerCount(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+	if count > s.threshold {
+		return s.source.Unpush(ctx, authorID)
+	}
+
+	followers, err := s.source.PushedFollowerIDs(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	if len(followers) == 0 {
+		return nil
+	}
+	return s.store.Push(ctx, followers, entry)
+}
+
+// Read returns up to limit entries of userID's home timeline after before.
+func (s *Service) Read(ctx context.Context, userID uuid.UUID, before *Entry, limit int) ([]Entry, error) {
+	pushed, err := s.store.Read(ctx, userID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Nothing was ever pushed here, because the user is new, follows only
+	// large accounts or an in-process store was restarted.
+	if len(pushed) == 0 {
+		return s.source.Pull(ctx, userID, true, before, limit)
+	}
+
+	pulled, err := s.source.Pull(ctx, userID, false, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	entries := merge(pushed, pulled, limit)
+
+	// A bounded store may have dropped older entries, so anything older is
+	// pulled for every followed account.
+	if len(pushed) < limit {
+		older, err := s.source.Pull(ctx, userID, true, &pushed[len(pushed)-1], limit)
+		if err != nil {
+			return nil, err
+		}
+		entries = merge(entries, older, limit)
+	}
+
+	return entries, nil
+}
+
+// newer reports whether a sorts before b in a timeline.
+func newer(a, b Entry) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return bytes.Compare(a.ChirpID[:], b.ChirpID[:]) > 0
+}
+
+// merge combines two timelines into one of at most limit entries, dropping
+// entries that appear in both.
+func merge(a, b []Entry, limit int) []Entry {
+	out := make([]Entry, 0, min(len(a)+len(b), limit))
+	i, j := 0, 0
+	for len(out) < limit && (i < len(a) || j < len(b)) {
+		var next Entry
+		switch {
+		case j >= len(b):
+			next, i = a[i], i+1
+		case i >= len(a):
+			next, j = b[j], j+1
+		case a[i].ChirpID == b[j].ChirpID:
+			next, i, j = a[i], i+1, j+1
+		case newer(a[i], b[j]):
+			next, i = a[i], i+1
+		default:
+			next, j = b[j], j+1
+		}
+		out = append(out, next)
+	}
+	return out
+}
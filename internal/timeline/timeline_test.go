@@ -0,0 +1,419 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type edge struct {
+	follower, followee uuid.UUID
+}
+
+type fakeSource struct {
+	chirps    map[uuid.UUID][]Entry
+	following map[uuid.UUID][]uuid.UUID
+	followers map[uuid.UUID][]uuid.UUID
+	pushed    map[edge]bool
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		chirps:    map[uuid.UUID][]Entry{},
+		following: map[uuid.UUID][]uuid.UUID{},
+		followers: map[uuid.UUID][]uuid.UUID{},
+		pushed:    map[edge]bool{},
+	}
+}
+
+func (f *fakeSource) follow(follower, followee uuid.UUID) {
+	f.following[follower] = append(f.following[follower], followee)
+	f.followers[followee] = append(f.followers[followee], follower)
+}
+
+func (f *fakeSource) unfollow(follower, followee uuid.UUID) {
+	f.following[follower] = slices.DeleteFunc(f.following[follower], func(id uuid.UUID) bool { return id == followee })
+	f.followers[followee] = slices.DeleteFunc(f.followers[followee], func(id uuid.UUID) bool { return id == follower })
+	delete(f.pushed, edge{follower, followee})
+}
+
+// followThrough records a follow and lets service decide how it is served.
+func followThrough(t testing.TB, service *Service, source *fakeSource, follower, followee uuid.UUID) {
+	t.Helper()
+	source.follow(follower, followee)
+	if err := service.Follow(context.Background(), follower, followee); err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+}
+
+// chirp records a chirp as newest for author.
+func (f *fakeSource) chirp(author uuid.UUID, createdAt time.Time) Entry {
+	entry := Entry{ChirpID: uuid.New(), CreatedAt: createdAt}
+	f.chirps[author] = append([]Entry{entry}, f.chirps[author]...)
+	return entry
+}
+
+func (f *fakeSource) FollowerCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return int64(len(f.followers[userID])), nil
+}
+
+func (f *fakeSource) PushedFollowerIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	out := []uuid.UUID{}
+	for _, follower := range f.followers[userID] {
+		if f.pushed[edge{follower, userID}] {
+			out = append(out, follower)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSource) SetPushed(ctx context.Context, followerID, followeeID uuid.UUID, pushed bool) error {
+	f.pushed[edge{followerID, followeeID}] = pushed
+	return nil
+}
+
+func (f *fakeSource) Unpush(ctx context.Context, userID uuid.UUID) error {
+	for _, follower := range f.followers[userID] {
+		delete(f.pushed, edge{follower, userID})
+	}
+	return nil
+}
+
+func (f *fakeSource) Chirps(ctx context.Context, userID uuid.UUID, limit int) ([]Entry, error) {
+	entries := f.chirps[userID]
+	return entries[:min(limit, len(entries))], nil
+}
+
+func (f *fakeSource) Pull(ctx context.Context, userID uuid.UUID, all bool, before *Entry, limit int) ([]Entry, error) {
+	authors := []uuid.UUID{userID}
+	for _, followee := range f.following[userID] {
+		if all || !f.pushed[edge{userID, followee}] {
+			authors = append(authors, followee)
+		}
+	}
+
+	out := []Entry{}
+	for _, author := range authors {
+		entries := f.chirps[author]
+		if before != nil {
+			start := sort.Search(len(entries), func(i int) bool {
+				return newer(*before, entries[i])
+			})
+			entries = entries[start:]
+		}
+		out = merge(out, entries, limit)
+	}
+	return out, nil
+}
+
+func TestPublishFansOutBelowThreshold(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	store := NewMemoryStore(100)
+	service := NewService(store, source, 10)
+
+	author, follower := uuid.New(), uuid.New()
+	followThrough(t, service, source, follower, author)
+	entry := source.chirp(author, time.Now())
+
+	if err := service.Publish(ctx, author, entry); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	got, _ := store.Read(ctx, follower, nil, 10)
+	if len(got) != 1 || got[0] != entry {
+		t.Errorf("store timeline = %v, want [%v]", got, entry)
+	}
+}
+
+func TestPublishSkipsAccountsAboveThreshold(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	store := NewMemoryStore(100)
+	service := NewService(store, source, 1)
+
+	author, follower, other := uuid.New(), uuid.New(), uuid.New()
+	followThrough(t, service, source, follower, author)
+	followThrough(t, service, source, other, author)
+	entry := source.chirp(author, time.Now())
+
+	if err := service.Publish(ctx, author, entry); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if got, _ := store.Read(ctx, follower, nil, 10); len(got) != 0 {
+		t.Errorf("store timeline = %v, want it empty", got)
+	}
+
+	got, err := service.Read(ctx, follower, nil, 10)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != entry {
+		t.Errorf("Read() = %v, want [%v]", got, entry)
+	}
+}
+
+func TestReadMergesPushedAndPulled(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	store := NewMemoryStore(100)
+	service := NewService(store, source, 1)
+
+	reader, small, large := uuid.New(), uuid.New(), uuid.New()
+	followThrough(t, service, source, reader, small)
+	followThrough(t, service, source, uuid.New(), large)
+	followThrough(t, service, source, reader, large)
+
+	start := time.Now()
+	want := []Entry{}
+	for i := 0; i < 6; i++ {
+		author := small
+		if i%2 == 0 {
+			author = large
+		}
+		entry := source.chirp(author, start.Add(time.Duration(i)*time.Minute))
+		if err := service.Publish(ctx, author, entry); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		want = append([]Entry{entry}, want...)
+	}
+
+	first, err := service.Read(ctx, reader, nil, 4)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	rest, err := service.Read(ctx, reader, &first[len(first)-1], 4)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	got := append(first, rest...)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Read() pages = %v, want %v", got, want)
+	}
+}
+
+func TestReadPullsWhenStoreIsCold(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	service := NewService(NewMemoryStore(100), source, 10)
+
+	reader, author := uuid.New(), uuid.New()
+	source.follow(reader, author)
+	entry := source.chirp(author, time.Now())
+
+	got, err := service.Read(ctx, reader, nil, 10)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(got) != 1 || got[0] != entry {
+		t.Errorf("Read() = %v, want [%v]", got, entry)
+	}
+}
+
+func TestFollowBackfillsTimeline(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	store := NewMemoryStore(100)
+	service := NewService(store, source, 10)
+
+	reader, author := uuid.New(), uuid.New()
+	start := time.Now()
+	old := source.chirp(author, start)
+	followThrough(t, service, source, reader, author)
+	entry := source.chirp(author, start.Add(time.Minute))
+	if err := service.Publish(ctx, author, entry); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	got, _ := store.Read(ctx, reader, nil, 10)
+	want := []Entry{entry, old}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("store timeline = %v, want %v", got, want)
+	}
+}
+
+func TestFollowPullsLongHistories(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	store := NewMemoryStore(100)
+	service := NewService(store, source, 2)
+
+	reader, author := uuid.New(), uuid.New()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		source.chirp(author, start.Add(time.Duration(i)*time.Minute))
+	}
+	followThrough(t, service, source, reader, author)
+
+	if source.pushed[edge{reader, author}] {
+		t.Errorf("follow of an account with more chirps than the threshold is pushed")
+	}
+	if got, _ := store.Read(ctx, reader, nil, 10); len(got) != 0 {
+		t.Errorf("store timeline = %v, want it empty", got)
+	}
+}
+
+func TestReadKeepsChirpsAcrossThreshold(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeSource()
+	service := NewService(NewMemoryStore(100), source, 1)
+
+	reader, author, other, friend := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	start := time.Now()
+
+	// reader's timeline is not empty, so Read does not pull everything.
+	followThrough(t, service, source, reader, friend)
+	recent := source.chirp(friend, start.Add(time.Hour))
+	if err := service.Publish(ctx, friend, recent); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	// author is pushed to reader, then grows past the threshold, then
+	// shrinks below it again.
+	followThrough(t, service, source, reader, author)
+	pushed := source.chirp(author, start)
+	if err := service.Publish(ctx, author, pushed); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	followThrough(t, service, source, other, author)
+	pulled := source.chirp(author, start.Add(time.Minute))
+	if err := service.Publish(ctx, author, pulled); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	source.unfollow(other, author)
+	later := source.chirp(author, start.Add(2*time.Minute))
+	if err := service.Publish(ctx, author, later); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	got, err := service.Read(ctx, reader, nil, 10)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := []Entry{recent, later, pulled, pushed}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryStoreKeepsNewestEntries(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(3)
+	user := uuid.New()
+
+	start := time.Now()
+	entries := []Entry{}
+	for i := 0; i < 5; i++ {
+		entries = append(entries, Entry{ChirpID: uuid.New(), CreatedAt: start.Add(time.Duration(i) * time.Second)})
+	}
+	// Push out of order, and one entry twice.
+	for _, i := range []int{2, 0, 4, 1, 3, 4} {
+		if err := store.Push(ctx, []uuid.UUID{user}, entries[i]); err != nil {
+			t.Fatalf("Push() error = %v", err)
+		}
+	}
+
+	got, _ := store.Read(ctx, user, nil, 10)
+	want := []Entry{entries[4], entries[3], entries[2]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+
+	got, _ = store.Read(ctx, user, &entries[3], 10)
+	want = []Entry{entries[2]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Read(before) = %v, want %v", got, want)
+	}
+}
+
+const (
+	benchUsers     = 500
+	benchFollowing = 100
+	benchChirps    = 20
+	benchPageSize  = 50
+)
+
+// benchmarkGraph builds a random follow graph where every user has written
+// benchChirps chirps, published through service as they were written.
+func benchmarkGraph(b *testing.B, threshold int64) (*Service, []uuid.UUID) {
+	b.Helper()
+	ctx := context.Background()
+	rng := rand.New(rand.NewSource(1))
+	source := newFakeSource()
+	service := NewService(NewMemoryStore(800), source, threshold)
+
+	users := make([]uuid.UUID, benchUsers)
+	for i := range users {
+		users[i] = uuid.New()
+	}
+	for i, user := range users {
+		for _, j := range rng.Perm(benchUsers)[:benchFollowing] {
+			if j != i {
+				followThrough(b, service, source, user, users[j])
+			}
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	for n := 0; n < benchChirps*benchUsers; n++ {
+		author := users[rng.Intn(benchUsers)]
+		entry := source.chirp(author, start.Add(time.Duration(n)*time.Millisecond))
+		if err := service.Publish(ctx, author, entry); err != nil {
+			b.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	return service, users
+}
+
+var strategies = []struct {
+	name      string
+	threshold int64
+}{
+	{name: "fan-out-on-write", threshold: math.MaxInt64},
+	{name: "fan-out-on-read", threshold: -1},
+}
+
+func BenchmarkPublish(b *testing.B) {
+	for _, strategy := range strategies {
+		b.Run(strategy.name, func(b *testing.B) {
+			service, users := benchmarkGraph(b, strategy.threshold)
+			ctx := context.Background()
+			now := time.Now()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				author := users[i%len(users)]
+				entry := Entry{ChirpID: uuid.New(), CreatedAt: now.Add(time.Duration(i) * time.Millisecond)}
+				if err := service.Publish(ctx, author, entry); err != nil {
+					b.Fatalf("Publish() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	for _, strategy := range strategies {
+		b.Run(strategy.name, func(b *testing.B) {
+			service, users := benchmarkGraph(b, strategy.threshold)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := service.Read(ctx, users[i%len(users)], nil, benchPageSize); err != nil {
+					b.Fatalf("Read() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,29 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/media"
+	"github.com/geolunalg/gochirpy/internal/moderation"
+	"github.com/geolunalg/gochirpy/internal/scheduler"
+	"github.com/geolunalg/gochirpy/internal/sweeper"
+	"github.com/geolunalg/gochirpy/internal/timeline"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
 )
@@ -16,7 +31,14 @@ import (
 type apiConfig struct {
 	fileserverHits atomic.Int32
 	db             *database.Queries
+	dbConn         *sql.DB
 	jwtSecret      string
+	timelines      *timeline.Service
+	maxPins        int
+	maxChirpLength int
+	reactions      map[string]struct{}
+	media          media.BlobStore
+	moderator      *moderation.Service
 }
 
 func main() {
@@ -43,35 +65,208 @@ func main() {
 	}
 	dbQueries := database.New(dbConn)
 
+	fanoutThreshold := int64(1000)
+	if raw := os.Getenv("FANOUT_THRESHOLD"); raw != "" {
+		fanoutThreshold, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			log.Fatalf("FANOUT_THRESHOLD must be an integer: %s", err)
+		}
+	}
+
+	maxChirpLength := 140
+	if raw := os.Getenv("MAX_CHIRP_LENGTH"); raw != "" {
+		maxChirpLength, err = strconv.Atoi(raw)
+		if err != nil || maxChirpLength <= 0 {
+			log.Fatalf("MAX_CHIRP_LENGTH must be a positive integer: %v", err)
+		}
+	}
+
+	maxPins := 3
+	if raw := os.Getenv("MAX_PINNED_CHIRPS"); raw != "" {
+		maxPins, err = strconv.Atoi(raw)
+		if err != nil || maxPins < 0 {
+			log.Fatalf("MAX_PINNED_CHIRPS must be a non-negative integer: %v", err)
+		}
+	}
+
+	schedulerInterval := 15 * time.Second
+	if raw := os.Getenv("SCHEDULER_INTERVAL"); raw != "" {
+		schedulerInterval, err = time.ParseDuration(raw)
+		if err != nil || schedulerInterval <= 0 {
+			log.Fatalf("SCHEDULER_INTERVAL must be a positive duration: %v", err)
+		}
+	}
+
+	sweepInterval := time.Minute
+	if raw := os.Getenv("SWEEP_INTERVAL"); raw != "" {
+		sweepInterval, err = time.ParseDuration(raw)
+		if err != nil || sweepInterval <= 0 {
+			log.Fatalf("SWEEP_INTERVAL must be a positive duration: %v", err)
+		}
+	}
+
+	moderationReload := time.Minute
+	if raw := os.Getenv("MODERATION_RELOAD_INTERVAL"); raw != "" {
+		moderationReload, err = time.ParseDuration(raw)
+		if err != nil || moderationReload <= 0 {
+			log.Fatalf("MODERATION_RELOAD_INTERVAL must be a positive duration: %v", err)
+		}
+	}
+
+	reactionEmoji := os.Getenv("REACTION_EMOJI")
+	if reactionEmoji == "" {
+		reactionEmoji = defaultReactions
+	}
+
+	// Uploads live under the file server root, which is told to hide them:
+	// they are only served through /media, which checks who may see them.
+	mediaStore, err := media.NewLocalStore(filepath.Join(filepathRoot, "media"))
+	if err != nil {
+		log.Fatalf("Error creating media directory: %s", err)
+	}
+
+	moderator := moderation.NewService(moderation.NewDBStore(dbQueries), moderationReload)
+	if err := moderator.Reload(context.Background()); err != nil {
+		log.Fatalf("Error loading moderation rules: %s", err)
+	}
+
+	var timelineStore timeline.Store
+	switch os.Getenv("TIMELINE_STORE") {
+	case "", "db":
+		timelineStore = timeline.NewDBStore(dbQueries)
+	case "memory":
+		// Only for a single instance: other replicas never see its entries.
+		timelineStore = timeline.NewMemoryStore(800)
+	default:
+		log.Fatal("TIMELINE_STORE must be db or memory")
+	}
+
 	apiCfg := apiConfig{
 		fileserverHits: atomic.Int32{},
 		db:             dbQueries,
+		dbConn:         dbConn,
 		jwtSecret:      jwtSecret,
+		timelines:      timeline.NewService(timelineStore, timeline.NewDBSource(dbQueries), fanoutThreshold),
+		maxPins:        maxPins,
+		maxChirpLength: maxChirpLength,
+		reactions:      parseReactions(reactionEmoji),
+		media:          mediaStore,
+		moderator:      moderator,
 	}
 
 	mux := http.NewServeMux()
-	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", http.FileServer(http.Dir(filepathRoot)))))
+	fileServer := middlewareHidePath("/media", http.FileServer(http.Dir(filepathRoot)))
+	mux.Handle("/app/", apiCfg.middlewareMetricsInc(http.StripPrefix("/app", fileServer)))
+
+	mux.HandleFunc("GET /media/{key}", apiCfg.handlerGetMedia)
 
 	mux.HandleFunc("GET /api/healthz", handlerReadiness)
 	// mux.HandleFunc("POST /api/validate_chirp", handlerChirpsValidate)
 	mux.HandleFunc("POST /api/users", apiCfg.handlerAddUser)
+	mux.HandleFunc("GET /api/users/me/mentions", apiCfg.handlerGetMyMentions)
+	mux.HandleFunc("GET /api/users/me/bookmarks", apiCfg.handlerGetMyBookmarks)
+	mux.HandleFunc("GET /api/users/me/blocks", apiCfg.handlerGetBlockedUsers)
+	mux.HandleFunc("POST /api/users/me/blocks/{userID}", apiCfg.handlerBlockUser)
+	mux.HandleFunc("DELETE /api/users/me/blocks/{userID}", apiCfg.handlerUnblockUser)
+	mux.HandleFunc("GET /api/users/me/mutes", apiCfg.handlerGetMutedUsers)
+	mux.HandleFunc("POST /api/users/me/mutes/{userID}", apiCfg.handlerMuteUser)
+	mux.HandleFunc("DELETE /api/users/me/mutes/{userID}", apiCfg.handlerUnmuteUser)
+	mux.HandleFunc("GET /api/users/me/scheduled", apiCfg.handlerGetScheduledChirps)
+	mux.HandleFunc("DELETE /api/users/me/scheduled/{chirpID}", apiCfg.handlerCancelScheduledChirp)
+	mux.HandleFunc("POST /api/users/me/pins/{chirpID}", apiCfg.handlerPinChirp)
+	mux.HandleFunc("DELETE /api/users/me/pins/{chirpID}", apiCfg.handlerUnpinChirp)
+	mux.HandleFunc("GET /api/users/{userID}", apiCfg.handlerGetUserProfile)
+	mux.HandleFunc("POST /api/users/{userID}/follow", apiCfg.handlerFollowUser)
+	mux.HandleFunc("DELETE /api/users/{userID}/follow", apiCfg.handlerUnfollowUser)
+	mux.HandleFunc("GET /api/users/{userID}/followers", apiCfg.handlerGetFollowers)
+	mux.HandleFunc("GET /api/users/{userID}/following", apiCfg.handlerGetFollowing)
+	mux.HandleFunc("GET /api/timeline", apiCfg.handlerGetTimeline)
 	mux.HandleFunc("POST /api/chirps", apiCfg.handlerAddChirp)
 	mux.HandleFunc("GET /api/chirps", apiCfg.handlerGetChirps)
+	mux.HandleFunc("GET /api/chirps/search", apiCfg.handlerSearchChirps)
 	mux.HandleFunc("GET /api/chirps/{chirpID}", apiCfg.handlerGetChirpById)
+	mux.HandleFunc("PUT /api/chirps/{chirpID}", apiCfg.handlerUpdateChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}", apiCfg.handlerDeleteChirp)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/revisions", apiCfg.handlerGetChirpRevisions)
+	mux.HandleFunc("GET /api/chirps/{chirpID}/thread", apiCfg.handlerGetChirpThread)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/likes", apiCfg.handlerLikeChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/likes", apiCfg.handlerUnlikeChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/bookmark", apiCfg.handlerBookmarkChirp)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/bookmark", apiCfg.handlerUnbookmarkChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reports", apiCfg.handlerReportChirp)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/poll/votes", apiCfg.handlerVoteInPoll)
+	mux.HandleFunc("POST /api/chirps/{chirpID}/reactions/{emoji}", apiCfg.handlerAddReaction)
+	mux.HandleFunc("DELETE /api/chirps/{chirpID}/reactions/{emoji}", apiCfg.handlerRemoveReaction)
+	mux.HandleFunc("POST /api/drafts", apiCfg.handlerCreateDraft)
+	mux.HandleFunc("GET /api/drafts", apiCfg.handlerGetDrafts)
+	mux.HandleFunc("GET /api/drafts/{draftID}", apiCfg.handlerGetDraft)
+	mux.HandleFunc("PUT /api/drafts/{draftID}", apiCfg.handlerUpdateDraft)
+	mux.HandleFunc("DELETE /api/drafts/{draftID}", apiCfg.handlerDeleteDraft)
+	mux.HandleFunc("POST /api/drafts/{draftID}/publish", apiCfg.handlerPublishDraft)
+	mux.HandleFunc("GET /api/hashtags/trending", apiCfg.handlerGetTrendingHashtags)
+	mux.HandleFunc("GET /api/hashtags/{tag}/chirps", apiCfg.handlerGetHashtagChirps)
 	mux.HandleFunc("POST /api/login", apiCfg.handlerLogin)
 	mux.HandleFunc("POST /api/refresh", apiCfg.handlerRefresh)
 	mux.HandleFunc("POST /api/revoke", apiCfg.handlerRevoke)
 
 	mux.HandleFunc("GET /admin/metrics", apiCfg.handlerMetrics)
 	mux.HandleFunc("POST /admin/reset", apiCfg.handlerReset)
+	mux.HandleFunc("POST /admin/chirps/{chirpID}/restore", apiCfg.handlerRestoreChirp)
+	mux.HandleFunc("GET /admin/reports", apiCfg.handlerGetReports)
+	mux.HandleFunc("POST /admin/reports/{reportID}/triage", apiCfg.handlerTriageReport)
+	mux.HandleFunc("POST /admin/reports/{reportID}/dismiss", apiCfg.handlerDismissReport)
+	mux.HandleFunc("POST /admin/reports/{reportID}/actions", apiCfg.handlerActOnReport)
+	mux.HandleFunc("GET /admin/moderation/actions", apiCfg.handlerGetModerationActions)
+	mux.HandleFunc("GET /admin/moderation/rules", apiCfg.handlerGetModerationRules)
+	mux.HandleFunc("POST /admin/moderation/rules", apiCfg.handlerCreateModerationRule)
+	mux.HandleFunc("PUT /admin/moderation/rules/{ruleID}", apiCfg.handlerUpdateModerationRule)
+	mux.HandleFunc("DELETE /admin/moderation/rules/{ruleID}", apiCfg.handlerDeleteModerationRule)
 
 	srv := &http.Server{
 		Handler: mux,
 		Addr:    ":" + port,
 	}
 
-	log.Printf("Serving on port: %s\n", port)
-	log.Fatal(srv.ListenAndServe())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	publisher := scheduler.NewWorker(scheduler.NewDBStore(dbQueries), schedulerInterval, 100, apiCfg.publishToTimelines)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		publisher.Run(ctx)
+	}()
+
+	expirySweeper := sweeper.New(sweeper.NewDBStore(dbQueries, mediaStore), sweepInterval, 500)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		expirySweeper.Run(ctx)
+	}()
+
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		moderator.Run(ctx)
+	}()
+
+	go func() {
+		log.Printf("Serving on port: %s\n", port)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Error shutting down server: %s", err)
+	}
+	workers.Wait()
 }
 
 func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
@@ -81,6 +276,20 @@ func (cfg *apiConfig) middlewareMetricsInc(next http.Handler) http.Handler {
 	})
 }
 
+// middlewareHidePath answers 404 for dir and everything below it, so a file
+// server can neither list nor serve it. The match ignores case because the
+// file system may.
+func middlewareHidePath(dir string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := strings.ToLower(path.Clean("/" + r.URL.Path))
+		if p == dir || strings.HasPrefix(p, dir+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (cfg *apiConfig) handlerMetrics(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "text/html; charset=utf-8")
 	w.WriteHeader(http.StatusOK)
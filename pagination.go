@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/geolunalg/gochirpy/internal/database"
+	"github.com/geolunalg/gochirpy/internal/pagination"
+	"github.com/google/uuid"
+)
+
+type chirpsPage struct {
+	Chirps     []returnVals `json:"chirps"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// firstKey and lastKey sort before and after every (created_at, id). Keyset
+// queries that compare against them instead of checking for a missing
+// cursor or time bound can always use the (created_at, id) indexes.
+var (
+	firstKey = pagination.Cursor{CreatedAt: time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC), ID: uuid.Nil}
+	lastKey  = pagination.Cursor{CreatedAt: time.Date(9999, 12, 31, 23, 59, 59, 0, time.UTC), ID: uuid.Max}
+)
+
+// keysetFrom returns the position a page continues from: the cursor, or
+// start on the first page.
+func keysetFrom(after *pagination.Cursor, start pagination.Cursor) pagination.Cursor {
+	if after == nil {
+		return start
+	}
+	return *after
+}
+
+// pageOf trims a result fetched with limit+1 rows down to limit and returns
+// the cursor for the following page, or "" when this is the last one.
+func pageOf[T any](items []T, limit int, cursorOf func(T) pagination.Cursor) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, cursorOf(items[len(items)-1]).Encode()
+}
+
+func chirpCursor(chirp database.Chirp) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: chirp.CreatedAt, ID: chirp.ID}
+}
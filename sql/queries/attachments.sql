@@ -0,0 +1,16 @@
+-- name: CreateChirpAttachment :exec
+INSERT INTO chirp_attachments (id, chirp_id, position, created_at, content_type, width, height, blob_key, thumbnail_key)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetChirpAttachments :many
+SELECT * FROM chirp_attachments
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+ORDER BY chirp_id, position;
+
+-- name: GetMediaAccess :one
+SELECT chirps.visibility,
+    chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)::boolean AS visible
+FROM chirp_attachments
+INNER JOIN chirps ON chirps.id = chirp_attachments.chirp_id
+WHERE chirp_attachments.blob_key = sqlc.arg(key)
+OR chirp_attachments.thumbnail_key = sqlc.arg(key);
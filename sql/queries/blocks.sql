@@ -0,0 +1,40 @@
+-- name: BlockUser :exec
+INSERT INTO blocks (blocker_id, blocked_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: UnblockUser :exec
+DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2;
+
+-- name: IsBlockedBetween :one
+SELECT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE (blocker_id = sqlc.arg(user_a) AND blocked_id = sqlc.arg(user_b))
+    OR (blocker_id = sqlc.arg(user_b) AND blocked_id = sqlc.arg(user_a))
+);
+
+-- name: GetBlockedUsers :many
+SELECT users.id, users.username, blocks.created_at
+FROM blocks
+INNER JOIN users ON users.id = blocks.blocked_id
+WHERE blocks.blocker_id = sqlc.arg(user_id)
+AND (blocks.created_at, users.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY blocks.created_at DESC, users.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: MuteUser :exec
+INSERT INTO mutes (muter_id, muted_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: UnmuteUser :exec
+DELETE FROM mutes WHERE muter_id = $1 AND muted_id = $2;
+
+-- name: GetMutedUsers :many
+SELECT users.id, users.username, mutes.created_at
+FROM mutes
+INNER JOIN users ON users.id = mutes.muted_id
+WHERE mutes.muter_id = sqlc.arg(user_id)
+AND (mutes.created_at, users.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY mutes.created_at DESC, users.id DESC
+LIMIT sqlc.arg(page_limit);
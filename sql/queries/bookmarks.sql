@@ -0,0 +1,17 @@
+-- name: BookmarkChirp :exec
+INSERT INTO chirp_bookmarks (user_id, chirp_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: UnbookmarkChirp :exec
+DELETE FROM chirp_bookmarks WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: GetBookmarkedChirps :many
+SELECT sqlc.embed(chirps), chirp_bookmarks.created_at AS bookmarked_at
+FROM chirp_bookmarks
+INNER JOIN chirps ON chirps.id = chirp_bookmarks.chirp_id
+WHERE chirp_bookmarks.user_id = sqlc.arg(user_id)
+AND chirp_visible_to(chirps.id, sqlc.arg(user_id))
+AND (chirp_bookmarks.created_at, chirps.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY chirp_bookmarks.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
@@ -1,11 +1,94 @@
 -- name: CreateChirp :one
-INSERT INTO chirps (id, created_at, updated_at, body, user_id)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO chirps (id, created_at, updated_at, body, user_id, in_reply_to, reposted_chirp_id, visibility, publish_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 RETURNING *;
 
--- name: GetChirps :many
+-- name: GetChirpsAsc :many
 SELECT * FROM chirps
-ORDER BY created_at ASC;
+WHERE (created_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+AND created_at >= sqlc.arg(since)::timestamp
+AND created_at <= sqlc.arg(until)::timestamp
+AND (sqlc.narg(author_id)::uuid IS NULL OR user_id = sqlc.narg(author_id)::uuid)
+AND chirp_visible_to(id, sqlc.arg(viewer_id)::uuid)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(viewer_id)::uuid AND mutes.muted_id = chirps.user_id
+)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetChirpsDesc :many
+SELECT * FROM chirps
+WHERE (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+AND created_at >= sqlc.arg(since)::timestamp
+AND created_at <= sqlc.arg(until)::timestamp
+AND (sqlc.narg(author_id)::uuid IS NULL OR user_id = sqlc.narg(author_id)::uuid)
+AND chirp_visible_to(id, sqlc.arg(viewer_id)::uuid)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(viewer_id)::uuid AND mutes.muted_id = chirps.user_id
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetUserChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+AND (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+AND chirp_visible_to(id, sqlc.arg(viewer_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
 
 -- name: GetChirpById :one
-SELECT * FROM chirps WHERE id = $1;
+SELECT * FROM chirps
+WHERE id = sqlc.arg(id)
+AND chirp_visible_to(id, sqlc.arg(viewer_id)::uuid);
+
+-- name: GetChirpsByIds :many
+SELECT * FROM chirps
+WHERE id = ANY(sqlc.arg(ids)::uuid[])
+AND chirp_visible_to(id, sqlc.arg(viewer_id)::uuid);
+
+-- name: GetOwnChirp :one
+SELECT * FROM chirps WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+
+-- name: GetChirpByIdForUpdate :one
+SELECT * FROM chirps WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: UpdateChirp :one
+UPDATE chirps SET body = $2, updated_at = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteChirp :exec
+UPDATE chirps SET deleted_at = $2
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreChirp :one
+UPDATE chirps SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: SearchChirps :many
+SELECT sqlc.embed(chirps),
+    ts_rank(chirps.search_vector, tsq)::real AS rank,
+    ts_headline(
+        'english',
+        replace(replace(replace(replace(replace(chirps.body,
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;'),
+        tsq,
+        'StartSel=<mark>, StopSel=</mark>'
+    )::text AS snippet
+FROM chirps, websearch_to_tsquery('english', sqlc.arg(query)) AS tsq
+WHERE chirps.search_vector @@ tsq
+AND chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)
+ORDER BY rank DESC, chirps.id ASC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: ChirpVisibleTo :one
+SELECT chirp_visible_to(sqlc.arg(chirp_id)::uuid, sqlc.arg(viewer_id)::uuid)::boolean AS visible;
+
+-- name: HideChirp :execrows
+UPDATE chirps SET hidden_at = $2
+WHERE id = $1 AND hidden_at IS NULL;
@@ -0,0 +1,23 @@
+-- name: CreateDraft :one
+INSERT INTO drafts (id, created_at, updated_at, user_id, body, in_reply_to, reposted_chirp_id, visibility)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetDraft :one
+SELECT * FROM drafts WHERE id = $1 AND user_id = $2;
+
+-- name: GetDrafts :many
+SELECT * FROM drafts
+WHERE user_id = sqlc.arg(user_id)
+AND (updated_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY updated_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: UpdateDraft :one
+UPDATE drafts
+SET body = $3, in_reply_to = $4, reposted_chirp_id = $5, visibility = $6, updated_at = $7
+WHERE id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: DeleteDraft :execrows
+DELETE FROM drafts WHERE id = $1 AND user_id = $2;
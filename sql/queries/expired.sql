@@ -0,0 +1,17 @@
+-- DeleteExpiredChirps reads the attachments from the snapshot taken before
+-- the delete cascades to them, so their blobs can be removed afterwards.
+-- name: DeleteExpiredChirps :many
+WITH purged AS (
+    DELETE FROM chirps
+    WHERE id IN (
+        SELECT id FROM chirps
+        WHERE expires_at <= sqlc.arg(now)::timestamp
+        ORDER BY expires_at ASC
+        LIMIT sqlc.arg(batch_size)
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id
+)
+SELECT purged.id, chirp_attachments.blob_key, chirp_attachments.thumbnail_key
+FROM purged
+LEFT JOIN chirp_attachments ON chirp_attachments.chirp_id = purged.id;
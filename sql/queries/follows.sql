@@ -0,0 +1,44 @@
+-- name: FollowUser :exec
+INSERT INTO follows (follower_id, followee_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: UnfollowUser :exec
+DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: GetFollowers :many
+SELECT users.id, users.username, follows.created_at AS followed_at
+FROM follows
+INNER JOIN users ON users.id = follows.follower_id
+WHERE follows.followee_id = sqlc.arg(user_id)
+AND (follows.created_at, users.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetFollowing :many
+SELECT users.id, users.username, follows.created_at AS followed_at
+FROM follows
+INNER JOIN users ON users.id = follows.followee_id
+WHERE follows.follower_id = sqlc.arg(user_id)
+AND (follows.created_at, users.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY follows.created_at DESC, users.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CountFollowers :one
+SELECT COUNT(*) FROM follows WHERE followee_id = $1;
+
+-- name: GetPushedFollowerIds :many
+SELECT follower_id FROM follows WHERE followee_id = $1 AND pushed;
+
+-- name: SetFollowPushed :exec
+UPDATE follows SET pushed = $3
+WHERE follower_id = $1 AND followee_id = $2;
+
+-- name: UnpushFollowers :exec
+UPDATE follows SET pushed = FALSE
+WHERE followee_id = $1 AND pushed;
+
+-- name: DeleteFollowsBetween :exec
+DELETE FROM follows
+WHERE (follower_id = sqlc.arg(user_a) AND followee_id = sqlc.arg(user_b))
+OR (follower_id = sqlc.arg(user_b) AND followee_id = sqlc.arg(user_a));
@@ -0,0 +1,34 @@
+-- name: UpsertHashtag :one
+INSERT INTO hashtags (id, created_at, tag)
+VALUES ($1, $2, $3)
+ON CONFLICT (tag) DO UPDATE SET tag = EXCLUDED.tag
+RETURNING *;
+
+-- name: AddChirpHashtag :exec
+INSERT INTO chirp_hashtags (chirp_id, hashtag_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpHashtags :exec
+DELETE FROM chirp_hashtags WHERE chirp_id = $1;
+
+-- name: GetChirpsByHashtag :many
+SELECT chirps.* FROM chirps
+INNER JOIN chirp_hashtags ON chirps.id = chirp_hashtags.chirp_id
+INNER JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE hashtags.tag = sqlc.arg(tag)
+AND chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)
+AND (chirps.created_at, chirps.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetTrendingHashtags :many
+SELECT hashtags.tag, COUNT(*) AS uses
+FROM chirps
+INNER JOIN chirp_hashtags ON chirp_hashtags.chirp_id = chirps.id
+INNER JOIN hashtags ON hashtags.id = chirp_hashtags.hashtag_id
+WHERE chirps.created_at >= sqlc.arg(since)::timestamp
+AND chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)
+GROUP BY hashtags.tag
+ORDER BY uses DESC, hashtags.tag ASC
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,16 @@
+-- name: LikeChirp :exec
+INSERT INTO chirp_likes (chirp_id, user_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: UnlikeChirp :exec
+DELETE FROM chirp_likes WHERE chirp_id = $1 AND user_id = $2;
+
+-- name: GetChirpLikeStats :many
+SELECT chirps.id AS chirp_id,
+    COUNT(chirp_likes.user_id) AS like_count,
+    COALESCE(BOOL_OR(chirp_likes.user_id = sqlc.arg(viewer_id)::uuid), false)::boolean AS liked_by_me
+FROM chirps
+LEFT JOIN chirp_likes ON chirp_likes.chirp_id = chirps.id
+WHERE chirps.id = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY chirps.id;
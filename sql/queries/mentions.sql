@@ -0,0 +1,22 @@
+-- name: AddChirpMentions :exec
+INSERT INTO chirp_mentions (chirp_id, user_id, created_at)
+SELECT sqlc.arg(chirp_id)::uuid, users.id, sqlc.arg(created_at)::timestamp
+FROM users
+WHERE users.username = ANY(sqlc.arg(usernames)::text[])
+AND NOT EXISTS (
+    SELECT 1 FROM blocks
+    WHERE blocks.blocker_id = users.id AND blocks.blocked_id = sqlc.arg(author_id)::uuid
+)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteChirpMentions :exec
+DELETE FROM chirp_mentions WHERE chirp_id = $1;
+
+-- name: GetMentionedChirps :many
+SELECT chirps.* FROM chirps
+INNER JOIN chirp_mentions ON chirps.id = chirp_mentions.chirp_id
+WHERE chirp_mentions.user_id = sqlc.arg(user_id)
+AND chirp_visible_to(chirps.id, sqlc.arg(user_id))
+AND (chirps.created_at, chirps.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
@@ -0,0 +1,9 @@
+-- name: LogModerationAction :exec
+INSERT INTO moderation_actions (id, created_at, moderator_id, action, report_id, chirp_id, target_user_id, details)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetModerationActions :many
+SELECT * FROM moderation_actions
+WHERE (created_at, id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
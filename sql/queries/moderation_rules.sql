@@ -0,0 +1,17 @@
+-- name: CreateModerationRule :one
+INSERT INTO moderation_rules (id, created_at, updated_at, kind, action, pattern)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetModerationRules :many
+SELECT * FROM moderation_rules
+ORDER BY created_at, id;
+
+-- name: UpdateModerationRule :one
+UPDATE moderation_rules
+SET kind = $2, action = $3, pattern = $4, updated_at = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteModerationRule :execrows
+DELETE FROM moderation_rules WHERE id = $1;
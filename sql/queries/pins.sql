@@ -0,0 +1,23 @@
+-- name: PinChirp :exec
+INSERT INTO chirp_pins (user_id, chirp_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: UnpinChirp :exec
+DELETE FROM chirp_pins WHERE user_id = $1 AND chirp_id = $2;
+
+-- name: DeleteChirpPins :exec
+DELETE FROM chirp_pins WHERE chirp_id = $1;
+
+-- name: GetPinnedChirpIds :many
+SELECT chirp_id FROM chirp_pins
+WHERE user_id = $1
+AND chirp_visible_to(chirp_id, user_id)
+ORDER BY created_at ASC, chirp_id ASC;
+
+-- name: GetPinnedChirps :many
+SELECT chirps.* FROM chirp_pins
+INNER JOIN chirps ON chirps.id = chirp_pins.chirp_id
+WHERE chirp_pins.user_id = sqlc.arg(user_id)
+AND chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)
+ORDER BY chirp_pins.created_at ASC, chirps.id ASC;
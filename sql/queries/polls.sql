@@ -0,0 +1,30 @@
+-- name: CreatePoll :exec
+INSERT INTO polls (chirp_id, created_at, closes_at)
+VALUES ($1, $2, $3);
+
+-- name: CreatePollOptions :exec
+INSERT INTO poll_options (id, chirp_id, position, text)
+SELECT options.id, sqlc.arg(chirp_id)::uuid, options.position::integer, options.text
+FROM unnest(sqlc.arg(ids)::uuid[], sqlc.arg(texts)::text[]) WITH ORDINALITY AS options(id, text, position);
+
+-- name: GetPoll :one
+SELECT * FROM polls WHERE chirp_id = $1;
+
+-- name: CastPollVote :execrows
+INSERT INTO poll_votes (chirp_id, user_id, option_id, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chirp_id, user_id) DO NOTHING;
+
+-- name: GetPollTallies :many
+SELECT polls.chirp_id,
+    polls.closes_at,
+    poll_options.id AS option_id,
+    poll_options.text,
+    COUNT(poll_votes.user_id) AS votes,
+    COALESCE(BOOL_OR(poll_votes.user_id = sqlc.arg(viewer_id)::uuid), false)::boolean AS voted_by_me
+FROM polls
+INNER JOIN poll_options ON poll_options.chirp_id = polls.chirp_id
+LEFT JOIN poll_votes ON poll_votes.option_id = poll_options.id
+WHERE polls.chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY polls.chirp_id, poll_options.id
+ORDER BY polls.chirp_id, poll_options.position;
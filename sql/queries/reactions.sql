@@ -0,0 +1,13 @@
+-- name: AddReaction :exec
+INSERT INTO chirp_reactions (chirp_id, user_id, emoji, created_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveReaction :exec
+DELETE FROM chirp_reactions WHERE chirp_id = $1 AND user_id = $2 AND emoji = $3;
+
+-- name: GetChirpReactionCounts :many
+SELECT chirp_id, emoji, COUNT(*) AS reactions
+FROM chirp_reactions
+WHERE chirp_id = ANY(sqlc.arg(chirp_ids)::uuid[])
+GROUP BY chirp_id, emoji;
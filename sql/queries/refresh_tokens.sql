@@ -9,7 +9,8 @@ INNER JOIN refresh_tokens
 ON users.id = refresh_tokens.user_id
 WHERE refresh_tokens.token = $1
 AND refresh_tokens.revoked_at IS NULL
-AND refresh_tokens.expires_at > NOW();
+AND refresh_tokens.expires_at > NOW()
+AND users.suspended_at IS NULL;
 
 -- name: RevokeRefreshToken :one
 UPDATE refresh_tokens SET revoked_at = NOW(),
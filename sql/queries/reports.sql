@@ -0,0 +1,46 @@
+-- name: CreateReport :one
+INSERT INTO reports (id, created_at, updated_at, chirp_id, reporter_id, reason, chirp_body, chirp_author_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetReport :one
+SELECT * FROM reports WHERE id = $1;
+
+-- name: GetReports :many
+SELECT sqlc.embed(reports),
+    chirps.hidden_at AS chirp_hidden_at,
+    (
+        SELECT COUNT(*) FROM reports AS others
+        WHERE others.chirp_id = reports.chirp_id
+        AND others.status IN ('open', 'triaged')
+    ) AS open_reports_for_chirp
+FROM reports
+LEFT JOIN chirps ON chirps.id = reports.chirp_id
+WHERE reports.status = sqlc.arg(status)
+AND (reports.created_at, reports.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY reports.created_at DESC, reports.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: TriageReport :one
+UPDATE reports SET status = 'triaged', updated_at = $2
+WHERE id = $1 AND status = 'open'
+RETURNING *;
+
+-- name: ResolveReport :one
+UPDATE reports
+SET status = sqlc.arg(status),
+    resolved_by = sqlc.arg(resolved_by),
+    resolved_at = sqlc.arg(now),
+    updated_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id) AND status IN ('open', 'triaged')
+RETURNING *;
+
+-- name: ResolveDuplicateReports :execrows
+UPDATE reports
+SET status = sqlc.arg(status),
+    resolved_by = sqlc.arg(resolved_by),
+    resolved_at = sqlc.arg(now),
+    updated_at = sqlc.arg(now)
+WHERE chirp_id = sqlc.arg(chirp_id)
+AND id <> sqlc.arg(id)
+AND status IN ('open', 'triaged');
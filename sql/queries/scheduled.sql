@@ -0,0 +1,31 @@
+-- name: PublishDueChirps :many
+UPDATE chirps
+SET created_at = sqlc.arg(now)::timestamp, updated_at = sqlc.arg(now)::timestamp, publish_at = NULL
+WHERE id IN (
+    SELECT id FROM chirps
+    WHERE publish_at <= sqlc.arg(now)::timestamp
+    AND deleted_at IS NULL
+    ORDER BY publish_at ASC
+    LIMIT sqlc.arg(batch_size)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: GetScheduledChirps :many
+SELECT * FROM chirps
+WHERE user_id = sqlc.arg(user_id)
+AND publish_at IS NOT NULL
+AND deleted_at IS NULL
+AND (publish_at, id) > (sqlc.arg(after_created_at)::timestamp, sqlc.arg(after_id)::uuid)
+ORDER BY publish_at ASC, id ASC
+LIMIT sqlc.arg(page_limit);
+
+-- name: CancelScheduledChirp :many
+WITH cancelled AS (
+    DELETE FROM chirps
+    WHERE id = $1 AND user_id = $2 AND publish_at IS NOT NULL
+    RETURNING id
+)
+SELECT cancelled.id, chirp_attachments.blob_key, chirp_attachments.thumbnail_key
+FROM cancelled
+LEFT JOIN chirp_attachments ON chirp_attachments.chirp_id = cancelled.id;
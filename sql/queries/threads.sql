@@ -0,0 +1,35 @@
+-- name: GetChirpAncestors :many
+WITH RECURSIVE ancestors (id, in_reply_to, depth) AS (
+    SELECT parent.id, parent.in_reply_to, 1
+    FROM chirps AS parent
+    INNER JOIN chirps AS child ON child.in_reply_to = parent.id
+    WHERE child.id = sqlc.arg(chirp_id)
+    UNION ALL
+    SELECT parent.id, parent.in_reply_to, ancestors.depth + 1
+    FROM chirps AS parent
+    INNER JOIN ancestors ON parent.id = ancestors.in_reply_to
+    WHERE ancestors.depth < sqlc.arg(max_depth)::int
+)
+SELECT sqlc.embed(chirps), ancestors.depth FROM chirps
+INNER JOIN ancestors ON chirps.id = ancestors.id
+WHERE chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)
+ORDER BY ancestors.depth DESC;
+
+-- name: GetChirpReplies :many
+WITH RECURSIVE replies (id, depth) AS (
+    SELECT reply.id, 1
+    FROM chirps AS reply
+    WHERE reply.in_reply_to = sqlc.arg(chirp_id)
+    AND reply.deleted_at IS NULL
+    UNION ALL
+    SELECT reply.id, replies.depth + 1
+    FROM chirps AS reply
+    INNER JOIN replies ON reply.in_reply_to = replies.id
+    WHERE reply.deleted_at IS NULL
+    AND replies.depth < sqlc.arg(max_depth)::int
+)
+SELECT sqlc.embed(chirps), replies.depth FROM chirps
+INNER JOIN replies ON chirps.id = replies.id
+WHERE chirp_visible_to(chirps.id, sqlc.arg(viewer_id)::uuid)
+ORDER BY replies.depth ASC, chirps.created_at ASC, chirps.id ASC
+LIMIT sqlc.arg(max_replies);
@@ -0,0 +1,55 @@
+-- name: AddTimelineEntries :exec
+INSERT INTO timeline_entries (user_id, chirp_id, created_at)
+SELECT unnest(sqlc.arg(user_ids)::uuid[]), sqlc.arg(chirp_id)::uuid, sqlc.arg(created_at)::timestamp
+ON CONFLICT DO NOTHING;
+
+-- name: BackfillTimelineEntries :exec
+INSERT INTO timeline_entries (user_id, chirp_id, created_at)
+SELECT sqlc.arg(user_id)::uuid, chirps.id, chirps.created_at FROM chirps
+WHERE chirps.id = ANY(sqlc.arg(chirp_ids)::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: GetTimelineEntries :many
+SELECT * FROM timeline_entries
+WHERE user_id = sqlc.arg(user_id)
+AND (created_at, chirp_id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY created_at DESC, chirp_id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetPulledTimelineEntries :many
+SELECT chirps.id, chirps.created_at FROM chirps
+WHERE chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+AND (
+    chirps.user_id = sqlc.arg(user_id)
+    OR chirps.user_id IN (
+        SELECT follows.followee_id FROM follows
+        WHERE follows.follower_id = sqlc.arg(user_id)
+        AND (sqlc.arg(all_follows)::boolean OR NOT follows.pushed)
+    )
+)
+AND (chirps.created_at, chirps.id) < (sqlc.arg(before_created_at)::timestamp, sqlc.arg(before_id)::uuid)
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetAuthorTimelineEntries :many
+SELECT chirps.id, chirps.created_at FROM chirps
+WHERE chirps.user_id = $1
+AND chirps.deleted_at IS NULL
+AND chirps.publish_at IS NULL
+ORDER BY chirps.created_at DESC, chirps.id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: GetTimelineChirps :many
+SELECT chirps.* FROM chirps
+WHERE chirps.id = ANY(sqlc.arg(ids)::uuid[])
+AND chirp_visible_to(chirps.id, sqlc.arg(user_id)::uuid)
+AND NOT EXISTS (
+    SELECT 1 FROM mutes
+    WHERE mutes.muter_id = sqlc.arg(user_id)::uuid AND mutes.muted_id = chirps.user_id
+)
+AND (
+    chirps.user_id = sqlc.arg(user_id)
+    OR chirps.user_id IN (SELECT followee_id FROM follows WHERE follower_id = sqlc.arg(user_id))
+)
+ORDER BY chirps.created_at DESC, chirps.id DESC;
@@ -1,10 +1,20 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, email, hashed_password)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO users (id, created_at, updated_at, email, hashed_password, username)
+VALUES ($1, $2, $3, $4, $5, $6)
 RETURNING *;
 
 -- name: DeleteUsers :exec
 DELETE FROM users;
 
 -- name: GetUserByEmail :one
-SELECT * FROM users WHERE email = $1;
\ No newline at end of file
+SELECT * FROM users WHERE email = $1;
+
+-- name: GetUserById :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: LockUser :exec
+SELECT id FROM users WHERE id = $1 FOR UPDATE;
+
+-- name: SuspendUser :execrows
+UPDATE users SET suspended_at = $2
+WHERE id = $1 AND suspended_at IS NULL;
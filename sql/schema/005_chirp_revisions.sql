@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE chirp_revisions (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    body VARCHAR(255) NOT NULL
+);
+
+-- +goose Down
+DROP TABLE chirp_revisions;
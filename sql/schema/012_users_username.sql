@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN username TEXT;
+UPDATE users SET username = 'user_' || substr(replace(id::text, '-', ''), 1, 10);
+ALTER TABLE users ALTER COLUMN username SET NOT NULL;
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN username;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- No foreign key on purpose: a repost must outlive its original, which is
+-- then shown as unavailable.
+ALTER TABLE chirps ADD COLUMN reposted_chirp_id UUID;
+CREATE INDEX chirps_reposted_chirp_id_idx ON chirps (reposted_chirp_id);
+CREATE UNIQUE INDEX chirps_rechirp_once_idx ON chirps (user_id, reposted_chirp_id)
+    WHERE body = '' AND deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX chirps_rechirp_once_idx;
+DROP INDEX chirps_reposted_chirp_id_idx;
+ALTER TABLE chirps DROP COLUMN reposted_chirp_id;
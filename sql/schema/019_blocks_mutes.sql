@@ -0,0 +1,40 @@
+-- +goose Up
+CREATE TABLE blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX blocks_blocked_id_idx ON blocks (blocked_id);
+
+CREATE TABLE mutes (
+    muter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    muted_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (muter_id, muted_id),
+    CHECK (muter_id <> muted_id)
+);
+
+-- chirp_visible_to is the single rule for whether a chirp may be shown to
+-- a viewer. viewer_id is the nil UUID for anonymous readers.
+-- +goose StatementBegin
+CREATE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION chirp_visible_to(UUID, UUID);
+DROP TABLE mutes;
+DROP TABLE blocks;
@@ -0,0 +1,53 @@
+-- +goose Up
+CREATE TYPE chirp_visibility AS ENUM ('public', 'followers', 'mentioned');
+
+ALTER TABLE chirps ADD COLUMN visibility chirp_visibility NOT NULL DEFAULT 'public';
+
+-- Authors always see their own chirps. Followers-only chirps are shown to
+-- the author's followers and mentioned-only chirps to the users they mention.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+        AND (
+            chirps.visibility = 'public'
+            OR chirps.user_id = viewer_id
+            OR (chirps.visibility = 'followers' AND EXISTS (
+                SELECT 1 FROM follows
+                WHERE follows.follower_id = viewer_id AND follows.followee_id = chirps.user_id
+            ))
+            OR (chirps.visibility = 'mentioned' AND EXISTS (
+                SELECT 1 FROM chirp_mentions
+                WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = viewer_id
+            ))
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+ALTER TABLE chirps DROP COLUMN visibility;
+
+DROP TYPE chirp_visibility;
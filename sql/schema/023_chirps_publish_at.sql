@@ -0,0 +1,66 @@
+-- +goose Up
+-- publish_at is set while a chirp is scheduled and cleared once the
+-- scheduler publishes it.
+ALTER TABLE chirps ADD COLUMN publish_at TIMESTAMP;
+
+CREATE INDEX chirps_publish_at_idx ON chirps (publish_at) WHERE publish_at IS NOT NULL;
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND chirps.publish_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+        AND (
+            chirps.visibility = 'public'
+            OR chirps.user_id = viewer_id
+            OR (chirps.visibility = 'followers' AND EXISTS (
+                SELECT 1 FROM follows
+                WHERE follows.follower_id = viewer_id AND follows.followee_id = chirps.user_id
+            ))
+            OR (chirps.visibility = 'mentioned' AND EXISTS (
+                SELECT 1 FROM chirp_mentions
+                WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = viewer_id
+            ))
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+        AND (
+            chirps.visibility = 'public'
+            OR chirps.user_id = viewer_id
+            OR (chirps.visibility = 'followers' AND EXISTS (
+                SELECT 1 FROM follows
+                WHERE follows.follower_id = viewer_id AND follows.followee_id = chirps.user_id
+            ))
+            OR (chirps.visibility = 'mentioned' AND EXISTS (
+                SELECT 1 FROM chirp_mentions
+                WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = viewer_id
+            ))
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+DROP INDEX chirps_publish_at_idx;
+
+ALTER TABLE chirps DROP COLUMN publish_at;
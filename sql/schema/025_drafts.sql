@@ -0,0 +1,18 @@
+-- +goose Up
+-- Drafts hold what the author typed. The body is neither length-checked nor
+-- cleaned until the draft is published as a chirp.
+CREATE TABLE drafts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    in_reply_to UUID,
+    reposted_chirp_id UUID,
+    visibility chirp_visibility NOT NULL DEFAULT 'public'
+);
+
+CREATE INDEX drafts_user_id_updated_at_idx ON drafts (user_id, updated_at DESC, id DESC);
+
+-- +goose Down
+DROP TABLE drafts;
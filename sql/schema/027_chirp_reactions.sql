@@ -0,0 +1,12 @@
+-- +goose Up
+-- Which emoji are accepted is configured on the server, not in the schema.
+CREATE TABLE chirp_reactions (
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (chirp_id, user_id, emoji)
+);
+
+-- +goose Down
+DROP TABLE chirp_reactions;
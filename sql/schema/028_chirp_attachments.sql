@@ -0,0 +1,17 @@
+-- +goose Up
+-- The image bytes live in the blob store; rows only hold their keys.
+CREATE TABLE chirp_attachments (
+    id UUID PRIMARY KEY,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    content_type TEXT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    blob_key TEXT NOT NULL,
+    thumbnail_key TEXT NOT NULL,
+    UNIQUE (chirp_id, position)
+);
+
+-- +goose Down
+DROP TABLE chirp_attachments;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE moderation_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('word', 'regex')),
+    action TEXT NOT NULL CHECK (action IN ('mask', 'reject')),
+    pattern TEXT NOT NULL,
+    UNIQUE (kind, action, pattern)
+);
+
+-- The words that used to be hard-coded in the server.
+INSERT INTO moderation_rules (id, created_at, updated_at, kind, action, pattern)
+SELECT gen_random_uuid(), NOW() AT TIME ZONE 'UTC', NOW() AT TIME ZONE 'UTC', 'word', 'mask', word
+FROM unnest(ARRAY['kerfuffle', 'sharbert', 'fornax']) AS word;
+
+-- +goose Down
+DROP TABLE moderation_rules;
@@ -0,0 +1,102 @@
+-- +goose Up
+ALTER TABLE chirps ADD COLUMN hidden_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    chirp_id UUID NOT NULL REFERENCES chirps(id) ON DELETE CASCADE,
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'triaged', 'dismissed', 'actioned')),
+    resolved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    UNIQUE (chirp_id, reporter_id)
+);
+
+CREATE INDEX reports_status_created_at_idx ON reports (status, created_at DESC, id DESC);
+
+-- The audit log has no foreign keys so that it outlives the users, chirps
+-- and reports it mentions.
+CREATE TABLE moderation_actions (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    moderator_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    report_id UUID,
+    chirp_id UUID,
+    target_user_id UUID,
+    details TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX moderation_actions_created_at_idx ON moderation_actions (created_at DESC, id DESC);
+
+-- Hidden chirps and chirps by suspended users are visible to nobody.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        INNER JOIN users AS authors ON authors.id = chirps.user_id
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND chirps.hidden_at IS NULL
+        AND authors.suspended_at IS NULL
+        AND chirps.publish_at IS NULL
+        AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW() AT TIME ZONE 'UTC')
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+        AND (
+            chirps.visibility = 'public'
+            OR chirps.user_id = viewer_id
+            OR (chirps.visibility = 'followers' AND EXISTS (
+                SELECT 1 FROM follows
+                WHERE follows.follower_id = viewer_id AND follows.followee_id = chirps.user_id
+            ))
+            OR (chirps.visibility = 'mentioned' AND EXISTS (
+                SELECT 1 FROM chirp_mentions
+                WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = viewer_id
+            ))
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION chirp_visible_to(target_chirp_id UUID, viewer_id UUID) RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM chirps
+        WHERE chirps.id = target_chirp_id
+        AND chirps.deleted_at IS NULL
+        AND chirps.publish_at IS NULL
+        AND (chirps.expires_at IS NULL OR chirps.expires_at > NOW() AT TIME ZONE 'UTC')
+        AND NOT EXISTS (
+            SELECT 1 FROM blocks
+            WHERE (blocks.blocker_id = chirps.user_id AND blocks.blocked_id = viewer_id)
+            OR (blocks.blocker_id = viewer_id AND blocks.blocked_id = chirps.user_id)
+        )
+        AND (
+            chirps.visibility = 'public'
+            OR chirps.user_id = viewer_id
+            OR (chirps.visibility = 'followers' AND EXISTS (
+                SELECT 1 FROM follows
+                WHERE follows.follower_id = viewer_id AND follows.followee_id = chirps.user_id
+            ))
+            OR (chirps.visibility = 'mentioned' AND EXISTS (
+                SELECT 1 FROM chirp_mentions
+                WHERE chirp_mentions.chirp_id = chirps.id AND chirp_mentions.user_id = viewer_id
+            ))
+        )
+    );
+$$ LANGUAGE SQL STABLE;
+-- +goose StatementEnd
+
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+
+ALTER TABLE users DROP COLUMN suspended_at;
+ALTER TABLE chirps DROP COLUMN hidden_at;
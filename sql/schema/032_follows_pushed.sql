@@ -0,0 +1,11 @@
+-- +goose Up
+-- pushed records how a follow is served: TRUE when the followee's chirps are
+-- written into the follower's timeline, FALSE when they are pulled at read
+-- time. Existing follows start out pulled, which needs no backfill.
+ALTER TABLE follows ADD COLUMN pushed BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX follows_followee_id_pushed_idx ON follows (followee_id) WHERE pushed;
+
+-- +goose Down
+DROP INDEX follows_followee_id_pushed_idx;
+ALTER TABLE follows DROP COLUMN pushed;
@@ -0,0 +1,30 @@
+-- +goose Up
+-- Reports are moderation evidence, so they outlive the chirps they are
+-- about. Each report keeps a copy of the chirp as it was reported, and
+-- chirp_id is cleared once the sweeper purges the chirp.
+ALTER TABLE reports ADD COLUMN chirp_body TEXT;
+ALTER TABLE reports ADD COLUMN chirp_author_id UUID;
+
+UPDATE reports SET chirp_body = chirps.body, chirp_author_id = chirps.user_id
+FROM chirps
+WHERE chirps.id = reports.chirp_id;
+
+ALTER TABLE reports ALTER COLUMN chirp_body SET NOT NULL;
+ALTER TABLE reports ALTER COLUMN chirp_author_id SET NOT NULL;
+
+ALTER TABLE reports DROP CONSTRAINT reports_chirp_id_fkey;
+ALTER TABLE reports ALTER COLUMN chirp_id DROP NOT NULL;
+ALTER TABLE reports ADD CONSTRAINT reports_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE SET NULL;
+
+-- +goose Down
+-- Reports on purged chirps can't point at a chirp again and are dropped.
+DELETE FROM reports WHERE chirp_id IS NULL;
+
+ALTER TABLE reports DROP CONSTRAINT reports_chirp_id_fkey;
+ALTER TABLE reports ALTER COLUMN chirp_id SET NOT NULL;
+ALTER TABLE reports ADD CONSTRAINT reports_chirp_id_fkey
+    FOREIGN KEY (chirp_id) REFERENCES chirps(id) ON DELETE CASCADE;
+
+ALTER TABLE reports DROP COLUMN chirp_author_id;
+ALTER TABLE reports DROP COLUMN chirp_body;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- GET /media/{key} looks the chirp up by either key to check who may see it.
+CREATE UNIQUE INDEX chirp_attachments_blob_key_idx ON chirp_attachments (blob_key);
+CREATE UNIQUE INDEX chirp_attachments_thumbnail_key_idx ON chirp_attachments (thumbnail_key);
+
+-- +goose Down
+DROP INDEX chirp_attachments_thumbnail_key_idx;
+DROP INDEX chirp_attachments_blob_key_idx;